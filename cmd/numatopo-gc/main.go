@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// numatopo-gc runs once per cluster and deletes the Numatopology objects whose Node no longer exists.
+package main
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	informers "volcano.sh/apis/pkg/client/informers/externalversions"
+
+	"volcano.sh/resource-exporter/pkg/args"
+	"volcano.sh/resource-exporter/pkg/numatopo"
+)
+
+var logFlushFreq = pflag.Duration("log-flush-frequency", 5*time.Second, "Maximum number of seconds between log flushes")
+
+func main() {
+	klog.InitFlags(nil)
+
+	opt := args.NewGCArgument()
+	opt.AddFlags(pflag.CommandLine)
+	cliflag.InitFlags()
+
+	go wait.Until(klog.Flush, *logFlushFreq, wait.NeverStop)
+	defer klog.Flush()
+
+	restConfig, err := args.BuildConfig(opt.KubeClientOptions)
+	if err != nil {
+		klog.Errorf("Build kube config failed, err = %v", err)
+		return
+	}
+	nodeInfoClient := versioned.NewForConfigOrDie(restConfig)
+	kubeClient := kubernetes.NewForConfigOrDie(restConfig)
+
+	kubeFactory := kubeinformers.NewSharedInformerFactory(kubeClient, opt.ResyncPeriod)
+	numaFactory := informers.NewSharedInformerFactory(nodeInfoClient, opt.ResyncPeriod)
+	gc, err := numatopo.NewOrphanCollector(kubeClient, nodeInfoClient, kubeFactory, numaFactory)
+	if err != nil {
+		klog.Fatalf("Failed to create orphan collector: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	kubeFactory.Start(ctx.Done())
+	numaFactory.Start(ctx.Done())
+	gc.Run(ctx, opt.Workers)
+}
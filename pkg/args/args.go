@@ -1,16 +1,37 @@
 package args
 
 import (
+	"os"
 	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	cliflag "k8s.io/component-base/cli/flag"
+
+	"volcano.sh/resource-exporter/pkg/features"
 )
 
 const (
-	defaultCheckInterval = 3 * time.Second
+	defaultCheckInterval   = 3 * time.Second
+	defaultHeartbeatPeriod = time.Minute
+	defaultResyncPeriod    = time.Minute
+
+	defaultProviderUpdateTimeout = 10 * time.Second
+	defaultRegressionHoldCycles  = 3
+	defaultMetricsBindAddress    = ":8080"
+
+	// KubeletConfigSourceFile reads the kubelet configuration from --kubelet-conf
+	KubeletConfigSourceFile = "file"
+	// KubeletConfigSourceConfigz reads the effective kubelet configuration from the /configz endpoint
+	KubeletConfigSourceConfigz = "configz"
+
+	// DefaultProvider is the provider selected unless --providers is set
+	DefaultProvider = "cpu"
+
+	// DefaultDRADriverName is the driver the ResourceSlices are published for unless --dra-driver-name is set
+	DefaultDRADriverName = "cpu.volcano.sh"
 )
 
 // ClientOptions used to build kube rest config.
@@ -21,37 +42,116 @@ type ClientOptions struct {
 
 // Argument is the object to save config set
 type Argument struct {
+	NodeName            string
 	CheckInterval       time.Duration
+	HeartbeatPeriod     time.Duration
+	WatchFiles          bool
+	WatchPods           bool
+	ResyncPeriod        time.Duration
+	HostRoot            string
+	KubeletRootDir      string
 	KubeletConf         string
+	KubeletConfigSource string
+	KubeletConfigzURL   string
+	KubeletInsecureTLS  bool
+	KubeletCAFile       string
 	DevicePath          string
+	PCIDevicePath       string
 	PodResourceSockPath string
 	CPUMngState         string
 	ResReserved         map[string]string
+	ReservationFromNode bool
+	PublishNRT          bool
+	PublishSlices       bool
+	PublishNodeLabels   bool
+	NFDFeaturesDir      string
+	DRADriverName       string
 	KubeClientOptions   ClientOptions
 
+	// Providers are the names of the NUMA info providers to collect with
+	Providers []string
+	// ProviderUpdateTimeout bounds the update of every NUMA info provider, 0 waits for them without a timeout
+	ProviderUpdateTimeout time.Duration
+
+	// RegressionHoldCycles is the number of periodic refreshes an implausible regression of the topology is held back for, 0 publishes it right away
+	RegressionHoldCycles int
+
 	// EnableGetCpuIDByPodResourceList enable get cpu id by PodResourcesLister API
 	EnableGetCpuIDByPodResourceList bool
+
+	// MetricsBindAddress is the address /metrics is served on, empty disables it
+	MetricsBindAddress string
+
+	// ConfigFile is the path of the Configuration file, the flags set on the command line take precedence over it
+	ConfigFile string
 }
 
 // NewArgument init the struct
 func NewArgument() *Argument {
 	return &Argument{
 		ResReserved: make(map[string]string),
+		Providers:   []string{DefaultProvider},
+	}
+}
+
+// DeepCopy returns a copy of args which shares nothing with it
+func (args *Argument) DeepCopy() *Argument {
+	out := *args
+	out.ResReserved = make(map[string]string, len(args.ResReserved))
+	for name, quantity := range args.ResReserved {
+		out.ResReserved[name] = quantity
 	}
+	out.Providers = append([]string(nil), args.Providers...)
+	return &out
+}
+
+// Validate returns the flags with unsupported values as one error, nil if they are valid.
+// The fields the Configuration also has are checked by Configuration.Validate.
+func (args *Argument) Validate() error {
+	var errs field.ErrorList
+	if source := args.KubeletConfigSource; source != KubeletConfigSourceFile && source != KubeletConfigSourceConfigz {
+		errs = append(errs, field.NotSupported(field.NewPath("kubelet-config-source"), source, []string{KubeletConfigSourceFile, KubeletConfigSourceConfigz}))
+	}
+	return errs.ToAggregate()
 }
 
 // AddFlags adds flags for a specific CMServer to the specified FlagSet.
 func (args *Argument) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&args.ConfigFile, "config", args.ConfigFile, "Path of the "+ConfigurationKind+" ("+ConfigurationAPIVersion+") file; it is reloaded when it changes or on SIGHUP, and the flags set on the command line take precedence over it")
+	fs.StringVar(&args.NodeName, "node-name", os.Getenv("MY_NODE_NAME"), "Name of the node the topology is published for; defaults to the MY_NODE_NAME environment variable")
 	fs.DurationVar(&args.CheckInterval, "check-period", defaultCheckInterval, "Burst to use while talking with kubernetes apiserver")
+	fs.BoolVar(&args.WatchFiles, "watch-files", true, "Refresh right after cpu_manager_state, the kubelet config or the sysfs online masks change, and otherwise only every --resync-period instead of every --check-period")
+	fs.BoolVar(&args.WatchPods, "watch-pods", true, "Refresh the CPU allocations right after a Guaranteed pod on this node starts running or a pod terminates")
+	fs.DurationVar(&args.ResyncPeriod, "resync-period", defaultResyncPeriod, "Period of the safety refresh while --watch-files is enabled")
+	fs.StringSliceVar(&args.Providers, "providers", args.Providers, "Comma-separated names of the topology providers to collect with; alpha and beta providers also need their feature gate")
+	fs.DurationVar(&args.ProviderUpdateTimeout, "provider-update-timeout", defaultProviderUpdateTimeout, "How long the update of every topology provider may take; a provider which times out keeps its last collected state until its update returns, 0 disables the timeout")
+	fs.IntVar(&args.RegressionHoldCycles, "regression-hold-cycles", defaultRegressionHoldCycles, "Number of periodic refreshes an implausible regression of the topology (the total capacity dropping to zero, NUMA nodes disappearing, every CPU becoming allocated at once) is held back for as a likely read error before it is published; refreshes triggered by file, sysfs or pod events are not counted, 0 publishes it right away")
+	fs.StringVar(&args.MetricsBindAddress, "metrics-bind-address", defaultMetricsBindAddress, "Address the Prometheus metrics are served on at /metrics; disabled if empty")
+	fs.DurationVar(&args.HeartbeatPeriod, "heartbeat-period", defaultHeartbeatPeriod, "How often the heartbeat of the conditions in the Numatopology is renewed when nothing changes; consumers should treat a Numatopology with an older heartbeat than a few periods as stale")
+	fs.StringVar(&args.HostRoot, "host-root", args.HostRoot, "Path the host filesystem is mounted at; prefixed to every auto-discovered path")
+	fs.StringVar(&args.KubeletRootDir, "kubelet-root-dir", args.KubeletRootDir, "Kubelet root directory on the host; discovered from the kubelet command line or well-known locations if empty")
 	fs.StringVar(&args.KubeletConf, "kubelet-conf", args.KubeletConf, "Path to kubelet configure file")
-	fs.StringVar(&args.DevicePath, "device-path", args.DevicePath, "Path to device information")
-	fs.StringVar(&args.CPUMngState, "cpu-manager-state", args.CPUMngState, "Path to cpu_manager_state")
+	fs.StringVar(&args.KubeletConfigSource, "kubelet-config-source", KubeletConfigSourceFile, "Where to read the kubelet configuration from, one of file or configz; with configz, --kubelet-conf is only used to cross-check")
+	fs.StringVar(&args.KubeletConfigzURL, "kubelet-configz-url", args.KubeletConfigzURL, "URL of the kubelet /configz endpoint (e.g. https://127.0.0.1:10250/configz); the API server node proxy is used if empty")
+	fs.BoolVar(&args.KubeletInsecureTLS, "kubelet-insecure-tls", args.KubeletInsecureTLS, "Do not verify the serving certificate of the kubelet when --kubelet-configz-url is set")
+	fs.StringVar(&args.KubeletCAFile, "kubelet-ca-file", args.KubeletCAFile, "CA file the serving certificate of the kubelet is verified with when --kubelet-configz-url is set; the CA of the API server is used if empty")
+	fs.StringVar(&args.DevicePath, "device-path", args.DevicePath, "Path to device information; <host-root>/sys/devices/system if empty")
+	fs.StringVar(&args.PCIDevicePath, "pci-device-path", args.PCIDevicePath, "Path to the PCI devices, read for the NUMA locality of devices; <host-root>/sys/bus/pci/devices if empty")
+	fs.StringVar(&args.CPUMngState, "cpu-manager-state", args.CPUMngState, "Path to cpu_manager_state; derived from the kubelet root directory if empty")
 	fs.Var(cliflag.NewMapStringString(&args.ResReserved), "res-reserved", "kubelet reserved resource  (e.g. cpu=200m,memory=500Mi")
+	fs.BoolVar(&args.ReservationFromNode, "reservation-from-node", args.ReservationFromNode, "Derive reserved resources from the Node status as capacity - allocatable, falling back to the kubelet configuration when the Node is not readable")
+	fs.BoolVar(&args.PublishNRT, "publish-nrt", args.PublishNRT, "Also publish the node topology as a NodeResourceTopology (topology.node.k8s.io/v1alpha2) object for schedulers which consume it")
+	fs.BoolVar(&args.PublishSlices, "publish-resource-slices", args.PublishSlices, "Also publish the CPUs of the node as Dynamic Resource Allocation ResourceSlice (resource.k8s.io/v1) devices with their NUMA node, socket, core and L3 cache as attributes")
+	fs.BoolVar(&args.PublishNodeLabels, "publish-node-labels", args.PublishNodeLabels, "Also write a summary of the topology (NUMA nodes, sockets, SMT, CPU and topology manager policy, topology hash) onto the Node as topology.volcano.sh/ labels and annotations")
+	fs.StringVar(&args.NFDFeaturesDir, "nfd-features-dir", args.NFDFeaturesDir, "Also write the topology as a Node Feature Discovery local feature file into this features.d directory (e.g. /etc/kubernetes/node-feature-discovery/features.d); disabled if empty")
+	fs.StringVar(&args.DRADriverName, "dra-driver-name", DefaultDRADriverName, "Driver name of the ResourceSlices published with --publish-resource-slices")
+
+	features.DefaultMutableFeatureGate.AddFlag(fs)
 
 	fs.StringVar(&args.KubeClientOptions.Master, "master", args.KubeClientOptions.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
 	fs.StringVar(&args.KubeClientOptions.KubeConfig, "kubeconfig", args.KubeClientOptions.KubeConfig, "Path to kubeconfig file with authorization and master location information.")
 
-	fs.StringVar(&args.PodResourceSockPath, "pod-resource-sock", "/var/lib/kubelet/pod-resources", "Path to pod-resource-sock")
+	fs.StringVar(&args.PodResourceSockPath, "pod-resource-sock", args.PodResourceSockPath, "Path to pod-resource-sock; derived from the kubelet root directory if empty")
 	fs.BoolVar(&args.EnableGetCpuIDByPodResourceList, "enable-pod-resource", true, "Enable get cpu id by PodResourcesLister; it is true by default")
 }
 
@@ -0,0 +1,396 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"volcano.sh/resource-exporter/pkg/features"
+)
+
+const (
+	// metricsDisabled is the metricsBindAddress disabling the metrics, as an empty one is defaulted
+	metricsDisabled = "0"
+
+	// ConfigurationAPIVersion is the apiVersion of the Configuration file
+	ConfigurationAPIVersion = "exporter.volcano.sh/v1alpha1"
+	// ConfigurationKind is the kind of the Configuration file
+	ConfigurationKind = "ExporterConfiguration"
+)
+
+// Configuration is the versioned configuration file of the exporter, set with --config.
+// Every field has the flag of the same meaning; the flags set on the command line take precedence.
+type Configuration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// NodeName is the node the topology is published for, --node-name
+	NodeName string `json:"nodeName,omitempty"`
+	// HostRoot is the path the host filesystem is mounted at, --host-root
+	HostRoot string `json:"hostRoot,omitempty"`
+	// CheckPeriod is how often the local state is collected without watching files, --check-period
+	CheckPeriod *metav1.Duration `json:"checkPeriod,omitempty"`
+	// ResyncPeriod is the period of the safety refresh while watching files, --resync-period
+	ResyncPeriod *metav1.Duration `json:"resyncPeriod,omitempty"`
+	// HeartbeatPeriod is how often the heartbeat of the conditions is renewed, --heartbeat-period
+	HeartbeatPeriod *metav1.Duration `json:"heartbeatPeriod,omitempty"`
+	// WatchFiles refreshes right after the local state changes, --watch-files
+	WatchFiles *bool `json:"watchFiles,omitempty"`
+	// WatchPods refreshes right after the pods of the node change, --watch-pods
+	WatchPods *bool `json:"watchPods,omitempty"`
+	// DevicePath is the sysfs system device path, --device-path
+	DevicePath string `json:"devicePath,omitempty"`
+	// PCIDevicePath is the sysfs PCI device path, --pci-device-path
+	PCIDevicePath string `json:"pciDevicePath,omitempty"`
+	// MetricsBindAddress is the address the metrics are served on, "0" disables them, --metrics-bind-address
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+
+	Kubelet          KubeletConfiguration          `json:"kubelet,omitempty"`
+	Reservation      ReservationConfiguration      `json:"reservation,omitempty"`
+	Providers        ProviderConfiguration         `json:"providers,omitempty"`
+	Publish          PublishConfiguration          `json:"publish,omitempty"`
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection,omitempty"`
+
+	// FeatureGates enables alpha and beta features, --feature-gates
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// KubeletConfiguration locates the kubelet files and endpoints the local state is read from
+type KubeletConfiguration struct {
+	// RootDir is the kubelet root directory, --kubelet-root-dir
+	RootDir string `json:"rootDir,omitempty"`
+	// ConfigFile is the kubelet configuration file, --kubelet-conf
+	ConfigFile string `json:"configFile,omitempty"`
+	// ConfigSource is where the kubelet configuration is read from, file or configz, --kubelet-config-source
+	ConfigSource string `json:"configSource,omitempty"`
+	// ConfigzURL is the kubelet /configz URL, --kubelet-configz-url
+	ConfigzURL string `json:"configzURL,omitempty"`
+	// InsecureTLS skips verifying the kubelet serving certificate, --kubelet-insecure-tls
+	InsecureTLS *bool `json:"insecureTLS,omitempty"`
+	// CAFile is the CA the kubelet serving certificate is verified with, --kubelet-ca-file
+	CAFile string `json:"caFile,omitempty"`
+	// CPUManagerState is the cpu_manager_state file, --cpu-manager-state
+	CPUManagerState string `json:"cpuManagerState,omitempty"`
+	// PodResourcesSocket is the pod-resources socket, --pod-resource-sock
+	PodResourcesSocket string `json:"podResourcesSocket,omitempty"`
+	// UsePodResources reads the CPU allocations from the PodResources API, --enable-pod-resource
+	UsePodResources *bool `json:"usePodResources,omitempty"`
+}
+
+// ReservationConfiguration is how the reserved resources are found
+type ReservationConfiguration struct {
+	// Reserved are the reserved resources by name, taking precedence over kubelet's, --res-reserved
+	Reserved map[string]string `json:"reserved,omitempty"`
+	// FromNode derives the reservation from the Node status, --reservation-from-node
+	FromNode *bool `json:"fromNode,omitempty"`
+}
+
+// ProviderConfiguration selects the topology providers and bounds their updates
+type ProviderConfiguration struct {
+	// Enabled are the names of the providers to collect with, --providers
+	Enabled []string `json:"enabled,omitempty"`
+	// UpdateTimeout bounds the update of every provider, 0 disables it, --provider-update-timeout
+	UpdateTimeout *metav1.Duration `json:"updateTimeout,omitempty"`
+}
+
+// PublishConfiguration is where and how the topology is published
+type PublishConfiguration struct {
+	// NRT also publishes a NodeResourceTopology, --publish-nrt
+	NRT *bool `json:"nrt,omitempty"`
+	// ResourceSlices also publishes DRA ResourceSlices, --publish-resource-slices
+	ResourceSlices *bool `json:"resourceSlices,omitempty"`
+	// DRADriverName is the driver of the ResourceSlices, --dra-driver-name
+	DRADriverName string `json:"draDriverName,omitempty"`
+	// NodeLabels also writes a summary onto the Node, --publish-node-labels
+	NodeLabels *bool `json:"nodeLabels,omitempty"`
+	// NFDFeaturesDir also writes a Node Feature Discovery feature file into this directory, --nfd-features-dir
+	NFDFeaturesDir string `json:"nfdFeaturesDir,omitempty"`
+	// RegressionHoldCycles is the number of periodic refreshes an implausible regression is held back for, --regression-hold-cycles
+	RegressionHoldCycles *int32 `json:"regressionHoldCycles,omitempty"`
+}
+
+// ClientConnectionConfiguration is how the API server is reached
+type ClientConnectionConfiguration struct {
+	// Master is the address of the API server, --master
+	Master string `json:"master,omitempty"`
+	// Kubeconfig is the kubeconfig file, --kubeconfig
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+}
+
+// LoadConfiguration reads the Configuration file at path, defaults and validates it.
+// Unknown fields are rejected, so a typo does not silently fall back to the default.
+func LoadConfiguration(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Configuration{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("decode %s failed, err: %v", path, err)
+	}
+	if c.APIVersion != ConfigurationAPIVersion || c.Kind != ConfigurationKind {
+		return nil, fmt.Errorf("%s has apiVersion %q and kind %q, expected %q and %q", path, c.APIVersion, c.Kind, ConfigurationAPIVersion, ConfigurationKind)
+	}
+	c.SetDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// SetDefaults sets the fields which are not set to the defaults of their flags.
+// The paths are left empty, they are discovered as for the flags.
+func (c *Configuration) SetDefaults() {
+	setDuration := func(d **metav1.Duration, value time.Duration) {
+		if *d == nil {
+			*d = &metav1.Duration{Duration: value}
+		}
+	}
+	setBool := func(b **bool, value bool) {
+		if *b == nil {
+			*b = &value
+		}
+	}
+
+	setDuration(&c.CheckPeriod, defaultCheckInterval)
+	setDuration(&c.ResyncPeriod, defaultResyncPeriod)
+	setDuration(&c.HeartbeatPeriod, defaultHeartbeatPeriod)
+	setBool(&c.WatchFiles, true)
+	setBool(&c.WatchPods, true)
+	if c.MetricsBindAddress == "" {
+		c.MetricsBindAddress = defaultMetricsBindAddress
+	}
+
+	if c.Kubelet.ConfigSource == "" {
+		c.Kubelet.ConfigSource = KubeletConfigSourceFile
+	}
+	setBool(&c.Kubelet.InsecureTLS, false)
+	setBool(&c.Kubelet.UsePodResources, true)
+
+	setBool(&c.Reservation.FromNode, false)
+
+	if len(c.Providers.Enabled) == 0 {
+		c.Providers.Enabled = []string{DefaultProvider}
+	}
+	setDuration(&c.Providers.UpdateTimeout, defaultProviderUpdateTimeout)
+
+	setBool(&c.Publish.NRT, false)
+	setBool(&c.Publish.ResourceSlices, false)
+	setBool(&c.Publish.NodeLabels, false)
+	if c.Publish.DRADriverName == "" {
+		c.Publish.DRADriverName = DefaultDRADriverName
+	}
+	if c.Publish.RegressionHoldCycles == nil {
+		cycles := int32(defaultRegressionHoldCycles)
+		c.Publish.RegressionHoldCycles = &cycles
+	}
+}
+
+// Validate returns the invalid fields of the defaulted c as one error, nil if it is valid
+func (c *Configuration) Validate() error {
+	var errs field.ErrorList
+	positive := func(path *field.Path, d *metav1.Duration) {
+		if d != nil && d.Duration <= 0 {
+			errs = append(errs, field.Invalid(path, d.Duration.String(), "must be greater than zero"))
+		}
+	}
+
+	positive(field.NewPath("checkPeriod"), c.CheckPeriod)
+	positive(field.NewPath("resyncPeriod"), c.ResyncPeriod)
+	positive(field.NewPath("heartbeatPeriod"), c.HeartbeatPeriod)
+
+	kubeletPath := field.NewPath("kubelet")
+	if source := c.Kubelet.ConfigSource; source != KubeletConfigSourceFile && source != KubeletConfigSourceConfigz {
+		errs = append(errs, field.NotSupported(kubeletPath.Child("configSource"), source, []string{KubeletConfigSourceFile, KubeletConfigSourceConfigz}))
+	}
+
+	reservedPath := field.NewPath("reservation", "reserved")
+	for name, quantity := range c.Reservation.Reserved {
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			errs = append(errs, field.Invalid(reservedPath.Key(name), quantity, err.Error()))
+		}
+	}
+
+	providersPath := field.NewPath("providers")
+	seen := sets.New[string]()
+	for i, name := range c.Providers.Enabled {
+		switch {
+		case name == "":
+			errs = append(errs, field.Required(providersPath.Child("enabled").Index(i), "provider name must not be empty"))
+		case seen.Has(name):
+			errs = append(errs, field.Duplicate(providersPath.Child("enabled").Index(i), name))
+		}
+		seen.Insert(name)
+	}
+	if d := c.Providers.UpdateTimeout; d != nil && d.Duration < 0 {
+		errs = append(errs, field.Invalid(providersPath.Child("updateTimeout"), d.Duration.String(), "must not be negative"))
+	}
+
+	if cycles := c.Publish.RegressionHoldCycles; cycles != nil && *cycles < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("publish", "regressionHoldCycles"), *cycles, "must not be negative"))
+	}
+
+	if len(c.FeatureGates) > 0 {
+		if err := features.DefaultFeatureGate.DeepCopy().SetFromMap(c.FeatureGates); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("featureGates"), c.FeatureGates, err.Error()))
+		}
+	}
+
+	return errs.ToAggregate()
+}
+
+// applyTo sets the fields of args from the defaulted c, except those set with a flag which changed
+// in fs. Empty paths and names are not applied, so the values of their flags are kept.
+func (c *Configuration) applyTo(args *Argument, fs *pflag.FlagSet) {
+	fromFile := func(flag string) bool {
+		return fs == nil || !fs.Changed(flag)
+	}
+	setString := func(flag string, dst *string, value string) {
+		if value != "" && fromFile(flag) {
+			*dst = value
+		}
+	}
+	setDuration := func(flag string, dst *time.Duration, value *metav1.Duration) {
+		if value != nil && fromFile(flag) {
+			*dst = value.Duration
+		}
+	}
+	setBool := func(flag string, dst *bool, value *bool) {
+		if value != nil && fromFile(flag) {
+			*dst = *value
+		}
+	}
+
+	setString("node-name", &args.NodeName, c.NodeName)
+	setString("host-root", &args.HostRoot, c.HostRoot)
+	setDuration("check-period", &args.CheckInterval, c.CheckPeriod)
+	setDuration("resync-period", &args.ResyncPeriod, c.ResyncPeriod)
+	setDuration("heartbeat-period", &args.HeartbeatPeriod, c.HeartbeatPeriod)
+	setBool("watch-files", &args.WatchFiles, c.WatchFiles)
+	setBool("watch-pods", &args.WatchPods, c.WatchPods)
+	setString("device-path", &args.DevicePath, c.DevicePath)
+	setString("pci-device-path", &args.PCIDevicePath, c.PCIDevicePath)
+	if c.MetricsBindAddress != "" && fromFile("metrics-bind-address") {
+		args.MetricsBindAddress = c.MetricsBindAddress
+		if c.MetricsBindAddress == metricsDisabled {
+			args.MetricsBindAddress = ""
+		}
+	}
+
+	setString("kubelet-root-dir", &args.KubeletRootDir, c.Kubelet.RootDir)
+	setString("kubelet-conf", &args.KubeletConf, c.Kubelet.ConfigFile)
+	setString("kubelet-config-source", &args.KubeletConfigSource, c.Kubelet.ConfigSource)
+	setString("kubelet-configz-url", &args.KubeletConfigzURL, c.Kubelet.ConfigzURL)
+	setBool("kubelet-insecure-tls", &args.KubeletInsecureTLS, c.Kubelet.InsecureTLS)
+	setString("kubelet-ca-file", &args.KubeletCAFile, c.Kubelet.CAFile)
+	setString("cpu-manager-state", &args.CPUMngState, c.Kubelet.CPUManagerState)
+	setString("pod-resource-sock", &args.PodResourceSockPath, c.Kubelet.PodResourcesSocket)
+	setBool("enable-pod-resource", &args.EnableGetCpuIDByPodResourceList, c.Kubelet.UsePodResources)
+
+	if c.Reservation.Reserved != nil && fromFile("res-reserved") {
+		args.ResReserved = make(map[string]string, len(c.Reservation.Reserved))
+		for name, quantity := range c.Reservation.Reserved {
+			args.ResReserved[name] = quantity
+		}
+	}
+	setBool("reservation-from-node", &args.ReservationFromNode, c.Reservation.FromNode)
+
+	if len(c.Providers.Enabled) > 0 && fromFile("providers") {
+		args.Providers = append([]string(nil), c.Providers.Enabled...)
+	}
+	setDuration("provider-update-timeout", &args.ProviderUpdateTimeout, c.Providers.UpdateTimeout)
+
+	setBool("publish-nrt", &args.PublishNRT, c.Publish.NRT)
+	setBool("publish-resource-slices", &args.PublishSlices, c.Publish.ResourceSlices)
+	setString("dra-driver-name", &args.DRADriverName, c.Publish.DRADriverName)
+	setBool("publish-node-labels", &args.PublishNodeLabels, c.Publish.NodeLabels)
+	setString("nfd-features-dir", &args.NFDFeaturesDir, c.Publish.NFDFeaturesDir)
+	if c.Publish.RegressionHoldCycles != nil && fromFile("regression-hold-cycles") {
+		args.RegressionHoldCycles = int(*c.Publish.RegressionHoldCycles)
+	}
+
+	setString("master", &args.KubeClientOptions.Master, c.ClientConnection.Master)
+	setString("kubeconfig", &args.KubeClientOptions.KubeConfig, c.ClientConnection.Kubeconfig)
+}
+
+// ConfigLoader builds the Argument from the flags and the --config file, it is called again to reload the file
+type ConfigLoader struct {
+	// base are the flags, the file is applied to a copy of them on every load
+	base  *Argument
+	flags *pflag.FlagSet
+
+	// featureGates are the feature gates of the file applied at the first load, they are not reloaded
+	featureGates map[string]bool
+	loaded       bool
+}
+
+// NewConfigLoader returns a ConfigLoader for args parsed from fs; the flags which changed in fs
+// take precedence over the file. A nil fs applies every field of the file.
+func NewConfigLoader(args *Argument, fs *pflag.FlagSet) *ConfigLoader {
+	return &ConfigLoader{base: args.DeepCopy(), flags: fs}
+}
+
+// Path returns the path of the configuration file, empty if there is none
+func (l *ConfigLoader) Path() string {
+	return l.base.ConfigFile
+}
+
+// Load returns a new Argument with the configuration file applied to the flags. The feature gates
+// of the file are set at the first load only, as the providers are selected with them at startup.
+func (l *ConfigLoader) Load() (*Argument, error) {
+	args := l.base.DeepCopy()
+	if l.base.ConfigFile == "" {
+		if err := args.Validate(); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+
+	c, err := LoadConfiguration(l.base.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	c.applyTo(args, l.flags)
+	// a flag set on the command line is not checked with the file
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
+	if l.flags == nil || !l.flags.Changed("feature-gates") {
+		if !l.loaded {
+			if err := features.DefaultMutableFeatureGate.SetFromMap(c.FeatureGates); err != nil {
+				return nil, fmt.Errorf("invalid feature gates in %s: %v", l.base.ConfigFile, err)
+			}
+			l.featureGates = c.FeatureGates
+		} else if !reflect.DeepEqual(l.featureGates, c.FeatureGates) {
+			klog.Warningf("Feature gates in %s changed to %v, they take effect after a restart", l.base.ConfigFile, c.FeatureGates)
+		}
+	}
+	l.loaded = true
+	return args, nil
+}
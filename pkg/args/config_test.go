@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const configHeader = "apiVersion: " + ConfigurationAPIVersion + "\nkind: " + ConfigurationKind + "\n"
+
+// parseFlags returns the Argument parsed from argv and the flag set it was parsed with
+func parseFlags(t *testing.T, argv ...string) (*Argument, *pflag.FlagSet) {
+	t.Helper()
+	args := NewArgument()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	args.AddFlags(fs)
+	if err := fs.Parse(argv); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return args, fs
+}
+
+func TestLoadConfigurationDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, configHeader)
+
+	c, err := LoadConfiguration(path)
+	if err != nil {
+		t.Fatalf("LoadConfiguration failed: %v", err)
+	}
+	if c.CheckPeriod.Duration != defaultCheckInterval || c.Providers.UpdateTimeout.Duration != defaultProviderUpdateTimeout {
+		t.Errorf("expected the flag defaults, got check period %v and update timeout %v", c.CheckPeriod, c.Providers.UpdateTimeout)
+	}
+	if !*c.WatchFiles || !*c.Kubelet.UsePodResources || *c.Publish.NRT {
+		t.Errorf("expected the boolean flag defaults, got %+v", c)
+	}
+	if !reflect.DeepEqual(c.Providers.Enabled, []string{DefaultProvider}) || *c.Publish.RegressionHoldCycles != defaultRegressionHoldCycles {
+		t.Errorf("expected the default providers and hold cycles, got %v and %d", c.Providers.Enabled, *c.Publish.RegressionHoldCycles)
+	}
+	if c.Kubelet.ConfigSource != KubeletConfigSourceFile || c.Publish.DRADriverName != DefaultDRADriverName {
+		t.Errorf("expected the default config source and driver, got %q and %q", c.Kubelet.ConfigSource, c.Publish.DRADriverName)
+	}
+
+	// a file without anything set gives the same Argument as the flag defaults
+	flags, fs := parseFlags(t)
+	fromFile := flags.DeepCopy()
+	c.applyTo(fromFile, fs)
+	if !reflect.DeepEqual(fromFile, flags) {
+		t.Errorf("expected the defaults of the file to match the flags, got %+v, expected %+v", fromFile, flags)
+	}
+}
+
+func TestLoadConfigurationErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unversioned",
+			content:  "checkPeriod: 1s\n",
+			expected: "apiVersion",
+		},
+		{
+			name:     "unknown field",
+			content:  configHeader + "checkInterval: 1s\n",
+			expected: "unknown field",
+		},
+		{
+			name:     "non-positive period",
+			content:  configHeader + "resyncPeriod: 0s\n",
+			expected: "resyncPeriod",
+		},
+		{
+			name:     "unknown config source",
+			content:  configHeader + "kubelet:\n  configSource: api\n",
+			expected: "kubelet.configSource",
+		},
+		{
+			name:     "invalid reservation",
+			content:  configHeader + "reservation:\n  reserved:\n    cpu: lots\n",
+			expected: "reservation.reserved[cpu]",
+		},
+		{
+			name:     "duplicate provider",
+			content:  configHeader + "providers:\n  enabled: [cpu, cpu]\n",
+			expected: "providers.enabled[1]",
+		},
+		{
+			name:     "negative hold cycles",
+			content:  configHeader + "publish:\n  regressionHoldCycles: -1\n",
+			expected: "publish.regressionHoldCycles",
+		},
+		{
+			name:     "unknown feature gate",
+			content:  configHeader + "featureGates:\n  NoSuchFeature: true\n",
+			expected: "featureGates",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, path, tc.content)
+			_, err := LoadConfiguration(path)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected an error about %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestConfigLoaderFlagsTakePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, configHeader+`checkPeriod: 5s
+heartbeatPeriod: 2m
+reservation:
+  reserved:
+    cpu: 500m
+providers:
+  enabled: [cpu]
+  updateTimeout: 3s
+`)
+	flags, fs := parseFlags(t, "--config="+path, "--heartbeat-period=30s", "--node-name=node-a")
+	loader := NewConfigLoader(flags, fs)
+
+	loaded, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.CheckInterval != 5*time.Second || loaded.ProviderUpdateTimeout != 3*time.Second {
+		t.Errorf("expected the periods of the file, got check period %v and update timeout %v", loaded.CheckInterval, loaded.ProviderUpdateTimeout)
+	}
+	if loaded.HeartbeatPeriod != 30*time.Second {
+		t.Errorf("expected --heartbeat-period to take precedence, got %v", loaded.HeartbeatPeriod)
+	}
+	if loaded.NodeName != "node-a" || !reflect.DeepEqual(loaded.ResReserved, map[string]string{"cpu": "500m"}) {
+		t.Errorf("expected the node name of the flag and the reservation of the file, got %q and %v", loaded.NodeName, loaded.ResReserved)
+	}
+	if flags.CheckInterval != defaultCheckInterval || len(flags.ResReserved) != 0 {
+		t.Errorf("expected the flags not to be modified, got %+v", flags)
+	}
+
+	// a reload applies the file to the flags again, so removed fields fall back to them
+	writeFile(t, path, configHeader+"checkPeriod: 7s\n")
+	reloaded, err := loader.Load()
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if reloaded.CheckInterval != 7*time.Second || len(reloaded.ResReserved) != 0 || reloaded.ProviderUpdateTimeout != defaultProviderUpdateTimeout {
+		t.Errorf("expected the reloaded file on top of the flags, got %+v", reloaded)
+	}
+
+	writeFile(t, path, configHeader+"checkPeriod: -1s\n")
+	if _, err := loader.Load(); err == nil {
+		t.Errorf("expected an invalid file to fail the reload")
+	}
+}
+
+func TestConfigLoaderRejectsInvalidFlags(t *testing.T) {
+	flags, fs := parseFlags(t, "--kubelet-config-source=configZ")
+	if _, err := NewConfigLoader(flags, fs).Load(); err == nil || !strings.Contains(err.Error(), "kubelet-config-source") {
+		t.Fatalf("expected --kubelet-config-source to be rejected, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, configHeader+"kubelet:\n  configSource: configz\n")
+	flags, fs = parseFlags(t, "--config="+path, "--kubelet-config-source=configZ")
+	if _, err := NewConfigLoader(flags, fs).Load(); err == nil || !strings.Contains(err.Error(), "kubelet-config-source") {
+		t.Fatalf("expected --kubelet-config-source to be rejected over a valid file, got %v", err)
+	}
+}
+
+func TestConfigLoaderWithoutFile(t *testing.T) {
+	flags, fs := parseFlags(t, "--check-period=1s")
+	loaded, err := NewConfigLoader(flags, fs).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, flags) {
+		t.Errorf("expected the flags, got %+v", loaded)
+	}
+}
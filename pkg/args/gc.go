@@ -0,0 +1,49 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultGCResyncPeriod = 10 * time.Minute
+	defaultGCWorkers      = 1
+)
+
+// GCArgument is the config of the Numatopology orphan collector
+type GCArgument struct {
+	ResyncPeriod      time.Duration
+	Workers           int
+	KubeClientOptions ClientOptions
+}
+
+// NewGCArgument init the struct
+func NewGCArgument() *GCArgument {
+	return &GCArgument{}
+}
+
+// AddFlags adds flags of the orphan collector to the specified FlagSet.
+func (args *GCArgument) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&args.ResyncPeriod, "resync-period", defaultGCResyncPeriod, "Period to check every Numatopology again, in case the deletion of its Node was missed")
+	fs.IntVar(&args.Workers, "workers", defaultGCWorkers, "Number of Numatopology objects checked concurrently")
+
+	fs.StringVar(&args.KubeClientOptions.Master, "master", args.KubeClientOptions.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
+	fs.StringVar(&args.KubeClientOptions.KubeConfig, "kubeconfig", args.KubeClientOptions.KubeConfig, "Path to kubeconfig file with authorization and master location information.")
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	defaultKubeletRootDir = "/var/lib/kubelet"
+	defaultDevicePath     = "/sys/devices/system"
+	defaultPCIDevicePath  = "/sys/bus/pci/devices"
+)
+
+// kubeletRootCandidates are the kubelet root directories used by common distributions
+var kubeletRootCandidates = []string{
+	defaultKubeletRootDir,
+	"/var/lib/k0s/kubelet",
+	"/var/snap/microk8s/common/var/lib/kubelet",
+}
+
+// kubeletProcessNames are the process names kubelet runs as, either standalone or embedded
+var kubeletProcessNames = map[string]bool{
+	"kubelet":    true,
+	"kubelite":   true,
+	"k3s":        true,
+	"k3s-agent":  true,
+	"k3s-server": true,
+}
+
+// kubeletFlags is the subset of kubelet command line flags needed to locate its files
+type kubeletFlags struct {
+	rootDir string
+	config  string
+}
+
+// ResolveKubeletPaths fills the kubelet and device paths that were not set explicitly.
+// The kubelet root directory is taken from --kubelet-root-dir, the running kubelet's
+// command line or the first well-known location that exists, in that order; the
+// state file, pod-resources socket and kubelet config are then derived from it.
+// Derived paths are prefixed with HostRoot, explicit ones are used as given.
+func (args *Argument) ResolveKubeletPaths() {
+	flags := findKubeletFlags(args.HostRoot)
+
+	rootDir := args.KubeletRootDir
+	rootSource := "flag"
+	if rootDir == "" && flags.rootDir != "" {
+		rootDir, rootSource = flags.rootDir, "kubelet command line"
+	}
+	if rootDir == "" {
+		rootDir, rootSource = defaultKubeletRootDir, "default"
+		for _, candidate := range kubeletRootCandidates {
+			if isDir(args.hostPath(candidate)) {
+				rootDir, rootSource = candidate, "well-known location"
+				break
+			}
+		}
+	}
+	args.KubeletRootDir = rootDir
+	klog.Infof("Using kubelet root directory %s (from %s)", args.hostPath(rootDir), rootSource)
+
+	if args.CPUMngState == "" {
+		args.CPUMngState = args.hostPath(filepath.Join(rootDir, "cpu_manager_state"))
+		klog.Infof("Using derived cpu manager state %s", args.CPUMngState)
+	}
+	if args.PodResourceSockPath == "" {
+		args.PodResourceSockPath = args.hostPath(filepath.Join(rootDir, "pod-resources"))
+		klog.Infof("Using derived pod-resources socket directory %s", args.PodResourceSockPath)
+	}
+	if args.DevicePath == "" {
+		args.DevicePath = args.hostPath(defaultDevicePath)
+		klog.Infof("Using derived device path %s", args.DevicePath)
+	}
+	if args.PCIDevicePath == "" {
+		args.PCIDevicePath = args.hostPath(defaultPCIDevicePath)
+		klog.Infof("Using derived PCI device path %s", args.PCIDevicePath)
+	}
+	if args.KubeletConf == "" {
+		conf := filepath.Join(rootDir, "config.yaml")
+		if flags.config != "" {
+			conf = flags.config
+		}
+		if _, err := os.Stat(args.hostPath(conf)); err == nil {
+			args.KubeletConf = args.hostPath(conf)
+			klog.Infof("Using derived kubelet config %s", args.KubeletConf)
+		}
+	}
+}
+
+func (args *Argument) hostPath(path string) string {
+	if args.HostRoot == "" {
+		return path
+	}
+	return filepath.Join(args.HostRoot, path)
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// findKubeletFlags scans <hostRoot>/proc for a kubelet process and parses its command line
+func findKubeletFlags(hostRoot string) kubeletFlags {
+	procDir := filepath.Join(hostRoot, "/proc")
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		klog.V(4).Infof("Skip kubelet command line discovery, err: %v", err)
+		return kubeletFlags{}
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.TrimLeft(entry.Name(), "0123456789") != "" {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "comm"))
+		if err != nil || !kubeletProcessNames[strings.TrimSpace(string(comm))] {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procDir, entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+
+		name := strings.TrimSpace(string(comm))
+		klog.V(4).Infof("Found %s process %s", name, entry.Name())
+		argv := strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00")
+		return parseKubeletCmdline(argv, name != "kubelet" && name != "kubelite")
+	}
+
+	return kubeletFlags{}
+}
+
+// parseKubeletCmdline understands both kubelet flags (--root-dir=/x, --root-dir /x)
+// and flags passed through by embedding distributions (--kubelet-arg=root-dir=/x).
+// For embedded kubelets only the passed-through flags are considered.
+func parseKubeletCmdline(argv []string, embedded bool) kubeletFlags {
+	flags := kubeletFlags{}
+	for i := 0; i < len(argv); i++ {
+		arg := strings.TrimLeft(argv[i], "-")
+		if arg == argv[i] {
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && i+1 < len(argv) && !strings.HasPrefix(argv[i+1], "-") {
+			i++
+			value = argv[i]
+		}
+		if name == "kubelet-arg" {
+			name, value, _ = strings.Cut(value, "=")
+		} else if embedded {
+			continue
+		}
+
+		switch name {
+		case "root-dir":
+			flags.rootDir = value
+		case "config":
+			flags.config = value
+		}
+	}
+
+	return flags
+}
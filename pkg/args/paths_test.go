@@ -0,0 +1,149 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mkdirAll(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", path, err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	mkdirAll(t, filepath.Dir(path))
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// writeProcess fakes /proc/<pid> with the given comm and NUL-separated cmdline
+func writeProcess(t *testing.T, hostRoot, pid, comm string, argv ...string) {
+	t.Helper()
+	writeFile(t, filepath.Join(hostRoot, "proc", pid, "comm"), comm+"\n")
+	writeFile(t, filepath.Join(hostRoot, "proc", pid, "cmdline"), strings.Join(argv, "\x00")+"\x00")
+}
+
+func TestParseKubeletCmdline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		argv     []string
+		embedded bool
+		expect   kubeletFlags
+	}{
+		{
+			name:   "equals form",
+			argv:   []string{"/usr/bin/kubelet", "--root-dir=/data/kubelet", "--config=/etc/kubelet.yaml"},
+			expect: kubeletFlags{rootDir: "/data/kubelet", config: "/etc/kubelet.yaml"},
+		},
+		{
+			name:   "separate value form",
+			argv:   []string{"kubelet", "--root-dir", "/data/kubelet", "--v", "2"},
+			expect: kubeletFlags{rootDir: "/data/kubelet"},
+		},
+		{
+			name:     "embedded only honours kubelet-arg",
+			argv:     []string{"k3s", "agent", "--config", "/etc/rancher/k3s/config.yaml", "--kubelet-arg=root-dir=/data/k3s-kubelet"},
+			embedded: true,
+			expect:   kubeletFlags{rootDir: "/data/k3s-kubelet"},
+		},
+		{
+			name:   "no flags",
+			argv:   []string{"kubelet"},
+			expect: kubeletFlags{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseKubeletCmdline(tc.argv, tc.embedded); got != tc.expect {
+				t.Fatalf("expected %+v, got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestResolveKubeletPaths(t *testing.T) {
+	t.Run("root dir from kubelet command line", func(t *testing.T) {
+		hostRoot := t.TempDir()
+		writeProcess(t, hostRoot, "1", "systemd", "/sbin/init")
+		writeProcess(t, hostRoot, "42", "kubelet", "/usr/bin/kubelet", "--root-dir=/data/kubelet", "--config=/etc/kubernetes/kubelet.yaml")
+		writeFile(t, filepath.Join(hostRoot, "etc/kubernetes/kubelet.yaml"), "")
+
+		opt := &Argument{HostRoot: hostRoot}
+		opt.ResolveKubeletPaths()
+
+		if opt.KubeletRootDir != "/data/kubelet" {
+			t.Fatalf("expected root dir /data/kubelet, got %q", opt.KubeletRootDir)
+		}
+		if want := filepath.Join(hostRoot, "data/kubelet/cpu_manager_state"); opt.CPUMngState != want {
+			t.Fatalf("expected %q, got %q", want, opt.CPUMngState)
+		}
+		if want := filepath.Join(hostRoot, "data/kubelet/pod-resources"); opt.PodResourceSockPath != want {
+			t.Fatalf("expected %q, got %q", want, opt.PodResourceSockPath)
+		}
+		if want := filepath.Join(hostRoot, "etc/kubernetes/kubelet.yaml"); opt.KubeletConf != want {
+			t.Fatalf("expected %q, got %q", want, opt.KubeletConf)
+		}
+		if want := filepath.Join(hostRoot, "sys/devices/system"); opt.DevicePath != want {
+			t.Fatalf("expected %q, got %q", want, opt.DevicePath)
+		}
+		if want := filepath.Join(hostRoot, "sys/bus/pci/devices"); opt.PCIDevicePath != want {
+			t.Fatalf("expected %q, got %q", want, opt.PCIDevicePath)
+		}
+	})
+
+	t.Run("root dir from well-known location", func(t *testing.T) {
+		hostRoot := t.TempDir()
+		mkdirAll(t, filepath.Join(hostRoot, "var/lib/k0s/kubelet"))
+
+		opt := &Argument{HostRoot: hostRoot}
+		opt.ResolveKubeletPaths()
+
+		if opt.KubeletRootDir != "/var/lib/k0s/kubelet" {
+			t.Fatalf("expected k0s root dir, got %q", opt.KubeletRootDir)
+		}
+		if opt.KubeletConf != "" {
+			t.Fatalf("kubelet config must stay empty when it does not exist, got %q", opt.KubeletConf)
+		}
+	})
+
+	t.Run("explicit paths are kept as given", func(t *testing.T) {
+		hostRoot := t.TempDir()
+		opt := &Argument{
+			HostRoot:            hostRoot,
+			KubeletRootDir:      "/custom",
+			CPUMngState:         "/state",
+			PodResourceSockPath: "/sock",
+			DevicePath:          "/dev-path",
+			PCIDevicePath:       "/pci-path",
+		}
+		opt.ResolveKubeletPaths()
+
+		if opt.KubeletRootDir != "/custom" || opt.CPUMngState != "/state" ||
+			opt.PodResourceSockPath != "/sock" || opt.DevicePath != "/dev-path" ||
+			opt.PCIDevicePath != "/pci-path" {
+			t.Fatalf("explicit paths were overridden: %+v", opt)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/featuregate"
+)
+
+// Every alpha or beta provider adds its feature gate here and to defaultFeatureGates, e.g.
+//
+//	// MemoryProvider enables the memory provider, selected with --providers=memory
+//	MemoryProvider featuregate.Feature = "MemoryProvider"
+//
+// and names it as the feature of its provider factory.
+
+// DefaultMutableFeatureGate is the feature gate set with --feature-gates
+var DefaultMutableFeatureGate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+// DefaultFeatureGate is the read-only view of DefaultMutableFeatureGate
+var DefaultFeatureGate featuregate.FeatureGate = DefaultMutableFeatureGate
+
+// defaultFeatureGates are the features of the exporter with their default and maturity
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{}
+
+func init() {
+	utilruntime.Must(DefaultMutableFeatureGate.Add(defaultFeatureGates))
+}
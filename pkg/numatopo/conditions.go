@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ConditionsAnnotation is the Numatopology annotation holding the health conditions of the exporter
+	// as a JSON list, the Numatopology has no status to hold them
+	ConditionsAnnotation = "volcano.sh/numatopo-conditions"
+	// AllocationSourceAnnotation is the Numatopology annotation recording where the CPU allocations were read from
+	AllocationSourceAnnotation = "volcano.sh/allocation-source"
+	// ExporterVersionAnnotation is the Numatopology annotation recording the version of the exporter
+	ExporterVersionAnnotation = "volcano.sh/exporter-version"
+
+	// AllocationSourcePodResources means the CPU allocations are read from the kubelet PodResources API
+	AllocationSourcePodResources = "pod-resources"
+	// AllocationSourceCPUManagerState means the CPU allocations are read from the kubelet cpu_manager_state file
+	AllocationSourceCPUManagerState = "cpu-manager-state"
+)
+
+// the types of the Numatopology conditions
+const (
+	// ConditionReady is True if the last collection of the topology and allocations succeeded, it is False
+	// while a part of them is published with its last-known-good value as listed in StaleAnnotation.
+	// The Numatopology is stale if it is False or its heartbeat is older than a few heartbeat periods
+	ConditionReady = "Ready"
+	// ConditionSourceDegraded is True if the CPU allocations are read from a less precise source than configured
+	ConditionSourceDegraded = "SourceDegraded"
+	// ConditionKubeletConfigReadable is True if the kubelet configuration could be read
+	ConditionKubeletConfigReadable = "KubeletConfigReadable"
+)
+
+// the reasons of the Numatopology conditions
+const (
+	reasonCollected              = "Collected"
+	reasonCollectionFailed       = "CollectionFailed"
+	reasonKubeletConfigRead      = "KubeletConfigRead"
+	reasonKubeletConfigReadError = "KubeletConfigReadFailed"
+	reasonConfiguredSource       = "ConfiguredSource"
+	reasonPodResourcesFailed     = "PodResourcesFailed"
+)
+
+// NumatopoCondition is a health condition of the exporter published in ConditionsAnnotation
+type NumatopoCondition struct {
+	Type   string                 `json:"type"`
+	Status metav1.ConditionStatus `json:"status"`
+	Reason string                 `json:"reason,omitempty"`
+	// Message is empty unless the condition is unhealthy
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	LastHeartbeatTime  metav1.Time `json:"lastHeartbeatTime"`
+}
+
+// GetConditions returns the current health conditions
+func (e *Exporter) GetConditions() []NumatopoCondition {
+	return e.conditions
+}
+
+// updateConditions sets the conditions from the result of the current collection. The heartbeat
+// is only renewed once it is older than heartbeatPeriod, so an unchanged topology is not written
+// on every refresh. If any condition is changed, return true.
+func (e *Exporter) updateConditions(now time.Time, heartbeatPeriod time.Duration, kubeletConfigErr, collectionErr error) bool {
+	desired := []NumatopoCondition{
+		{Type: ConditionReady, Status: metav1.ConditionTrue, Reason: reasonCollected},
+		{Type: ConditionSourceDegraded, Status: metav1.ConditionFalse, Reason: reasonConfiguredSource},
+		{Type: ConditionKubeletConfigReadable, Status: metav1.ConditionTrue, Reason: reasonKubeletConfigRead},
+	}
+	if collectionErr != nil {
+		desired[0].Status, desired[0].Reason, desired[0].Message = metav1.ConditionFalse, reasonCollectionFailed, collectionErr.Error()
+	}
+	if e.isAllocationFallback() {
+		desired[1].Status, desired[1].Reason = metav1.ConditionTrue, reasonPodResourcesFailed
+		desired[1].Message = "CPU allocations are read from cpu_manager_state as the PodResources API failed"
+	}
+	if kubeletConfigErr != nil {
+		desired[2].Status, desired[2].Reason, desired[2].Message = metav1.ConditionFalse, reasonKubeletConfigReadError, kubeletConfigErr.Error()
+	}
+
+	previous := make(map[string]NumatopoCondition, len(e.conditions))
+	for _, condition := range e.conditions {
+		previous[condition.Type] = condition
+	}
+
+	isChange := len(e.conditions) != len(desired)
+	for i := range desired {
+		condition := &desired[i]
+		old, ok := previous[condition.Type]
+		if !ok || old.Status != condition.Status {
+			klog.V(2).Infof("Condition %s changed to %s, reason: %s", condition.Type, condition.Status, condition.Reason)
+			condition.LastTransitionTime = metav1.NewTime(now)
+			isChange = true
+			continue
+		}
+
+		condition.LastTransitionTime, condition.LastHeartbeatTime = old.LastTransitionTime, old.LastHeartbeatTime
+		if old.Reason != condition.Reason || old.Message != condition.Message ||
+			now.Sub(old.LastHeartbeatTime.Time) >= heartbeatPeriod {
+			isChange = true
+		}
+	}
+
+	// every write of the conditions renews the heartbeat of all of them
+	if isChange {
+		for i := range desired {
+			desired[i].LastHeartbeatTime = metav1.NewTime(now)
+		}
+	}
+	e.conditions = desired
+	return isChange
+}
+
+// conditionsAnnotation returns the value of ConditionsAnnotation, empty if there is no condition yet
+func (e *Exporter) conditionsAnnotation() string {
+	if len(e.conditions) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(e.conditions)
+	if err != nil {
+		klog.Errorf("Marshal conditions failed, err: %v", err)
+		return ""
+	}
+	return string(data)
+}
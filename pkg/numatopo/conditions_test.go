@@ -0,0 +1,79 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateConditions(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	condition := func(conditionType string) NumatopoCondition {
+		for _, c := range e.GetConditions() {
+			if c.Type == conditionType {
+				return c
+			}
+		}
+		t.Fatalf("condition %s not found in %v", conditionType, e.GetConditions())
+		return NumatopoCondition{}
+	}
+
+	if !e.updateConditions(start, time.Minute, nil, nil) {
+		t.Fatalf("expected a change on first update")
+	}
+	if ready := condition(ConditionReady); ready.Status != metav1.ConditionTrue || !ready.LastHeartbeatTime.Time.Equal(start) {
+		t.Fatalf("unexpected Ready condition %+v", ready)
+	}
+
+	// the heartbeat is not renewed within the heartbeat period
+	if e.updateConditions(start.Add(30*time.Second), time.Minute, nil, nil) {
+		t.Fatalf("expected no change within the heartbeat period")
+	}
+	later := start.Add(time.Minute)
+	if !e.updateConditions(later, time.Minute, nil, nil) {
+		t.Fatalf("expected the heartbeat to be renewed after the heartbeat period")
+	}
+	if ready := condition(ConditionReady); !ready.LastHeartbeatTime.Time.Equal(later) || !ready.LastTransitionTime.Time.Equal(start) {
+		t.Fatalf("expected heartbeat %v and transition %v, got %+v", later, start, ready)
+	}
+
+	failed := later.Add(time.Second)
+	e.allocationFallback = true
+	if !e.updateConditions(failed, time.Minute, errors.New("permission denied"), errors.New("get cpu detail failed")) {
+		t.Fatalf("expected a change on failure")
+	}
+	for _, conditionType := range []string{ConditionReady, ConditionKubeletConfigReadable} {
+		if c := condition(conditionType); c.Status != metav1.ConditionFalse || !c.LastTransitionTime.Time.Equal(failed) || c.Message == "" {
+			t.Fatalf("unexpected %s condition %+v", conditionType, c)
+		}
+	}
+	if degraded := condition(ConditionSourceDegraded); degraded.Status != metav1.ConditionTrue {
+		t.Fatalf("unexpected SourceDegraded condition %+v", degraded)
+	}
+
+	var published []NumatopoCondition
+	if err := json.Unmarshal([]byte(e.conditionsAnnotation()), &published); err != nil || len(published) != 3 {
+		t.Fatalf("unexpected conditions annotation %q, err: %v", e.conditionsAnnotation(), err)
+	}
+}
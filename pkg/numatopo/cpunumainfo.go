@@ -24,8 +24,10 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	podresv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
 	cpustate "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
@@ -39,23 +41,58 @@ import (
 
 const resourceCPU = "cpu"
 
+// GetAllocationSource returns where the last CPU allocations were read from
+func (e *Exporter) GetAllocationSource() string {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+	return e.allocationSource
+}
+
+// isAllocationFallback returns true while the CPU allocations are read from cpu_manager_state as the PodResources API fails
+func (e *Exporter) isAllocationFallback() bool {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+	return e.allocationFallback
+}
+
+// the fields of the cpu provider which are marked stale individually
+const (
+	cpuFieldTopology    = "topology"
+	cpuFieldAllocations = "allocations"
+	cpuFieldCPUDetail   = "cpuDetail"
+	cpuFieldPCIDevices  = "pciDevices"
+)
+
 // CPUNumaInfo is the object to maintain the cpu information
 type CPUNumaInfo struct {
+	// exporter provides the PodResources client and keeps the allocation source and collection errors
+	exporter *Exporter
+
 	NUMANodes   []int
 	NUMA2CpuCap map[int]int
 	cpu2NUMA    map[int]int
 	cpuDetail   map[int]v1alpha1.CPUInfo
+	// cpuL3Cache is the L3 cache domain of every cpu which has one
+	cpuL3Cache map[int]int
+	// pciDevices is the NUMA locality of the PCI devices, by address
+	pciDevices map[string]PCIDevice
 
 	NUMA2FreeCpus  map[int][]int
 	podAllocations []v1alpha1.PodAllocation
+
+	// stale are the fields which could not be read in the last update and keep their previous value
+	stale []StaleField
 }
 
-// NewCPUNumaInfo init CPUNumaInfo struct object
-func NewCPUNumaInfo() *CPUNumaInfo {
+// NewCPUNumaInfo init CPUNumaInfo struct object for the node of exporter
+func NewCPUNumaInfo(exporter *Exporter) *CPUNumaInfo {
 	numaInfo := &CPUNumaInfo{
+		exporter:      exporter,
 		NUMA2CpuCap:   make(map[int]int),
 		cpu2NUMA:      make(map[int]int),
 		cpuDetail:     make(map[int]v1alpha1.CPUInfo),
+		cpuL3Cache:    make(map[int]int),
+		pciDevices:    make(map[string]PCIDevice),
 		NUMA2FreeCpus: make(map[int][]int),
 	}
 
@@ -67,41 +104,37 @@ func (info *CPUNumaInfo) Name() string {
 	return resourceCPU
 }
 
-func getNumaOnline(onlinePath string) []int {
+func getNumaOnline(onlinePath string) ([]int, error) {
 	data, err := ioutil.ReadFile(onlinePath)
 	if err != nil {
-		klog.Errorf("Read numa online file failed, err=%v.", err)
-		return []int{}
+		return nil, fmt.Errorf("read numa online file failed, err: %v", err)
 	}
 
 	nodeList, apiErr := util.Parse(string(data))
 	if apiErr != nil {
-		klog.Errorf("Parse numa online file failed, err=%v.", apiErr)
-		return []int{}
+		return nil, fmt.Errorf("parse numa online file failed, err: %v", apiErr)
 	}
 
-	return nodeList
+	return nodeList, nil
 }
 
 func (info *CPUNumaInfo) cpu2numa(cpuid int) int {
 	return info.cpu2NUMA[cpuid]
 }
 
-func getNumaNodeCpuCap(nodePath string, nodeID int) []int {
+func getNumaNodeCpuCap(nodePath string, nodeID int) ([]int, error) {
 	cpuPath := filepath.Join(nodePath, fmt.Sprintf("node%d", nodeID), "cpulist")
 	data, err := ioutil.ReadFile(cpuPath)
 	if err != nil {
-		klog.Errorf("Read node%d cpulist file failed, err: %v", nodeID, err)
-		return nil
+		return nil, fmt.Errorf("read node%d cpulist file failed, err: %v", nodeID, err)
 	}
 
 	cpuList, apiErr := util.Parse(string(data))
 	if apiErr != nil {
-		klog.Errorf("Parse node%d cpulist file failed, err: %v", nodeID, apiErr)
-		return nil
+		return nil, fmt.Errorf("parse node%d cpulist file failed, err: %v", nodeID, apiErr)
 	}
 
-	return cpuList
+	return cpuList, nil
 }
 
 // getFreeCPUListAndPodAllocationsByManagerState returns a list of free (unallocated) CPU IDs and a list of pod cpu allocations by reading the cpu_manager_state file
@@ -144,7 +177,7 @@ func getFreeCPUListAndPodAllocationsByManagerState(cpuMngState string) ([]int, [
 }
 
 // GetFreeCPUListAndPodAllocationsByPodResources returns a list of free (unallocated) CPU IDs and a list of pod cpu allocations by calling the PodResources API.
-func GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA map[int]int) ([]int, []v1alpha1.PodAllocation, error) {
+func GetFreeCPUListAndPodAllocationsByPodResources(client podresv1.PodResourcesListerClient, cpu2NUMA map[int]int) ([]int, []v1alpha1.PodAllocation, error) {
 	if client == nil {
 		return nil, nil, fmt.Errorf("PodResourcesListerClient is not initialized")
 	}
@@ -214,24 +247,60 @@ func GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA map[int]int) ([]int,
 	return freeCPUs, podAllocations, nil
 }
 
-func (info *CPUNumaInfo) numaCapUpdate(numaPath string) {
-	for _, node := range info.NUMANodes {
-		cpuList := getNumaNodeCpuCap(numaPath, node)
-		info.NUMA2CpuCap[node] = len(cpuList)
+// numaTopologyUpdate reads the online NUMA nodes and their cpus. Nothing is set unless all of them
+// could be read, a missing node would otherwise look like its cpus went offline.
+func (info *CPUNumaInfo) numaTopologyUpdate(numaPath string) error {
+	nodes, err := getNumaOnline(filepath.Join(numaPath, "online"))
+	if err != nil {
+		return err
+	}
+
+	numa2CpuCap := make(map[int]int, len(nodes))
+	cpu2NUMA := make(map[int]int)
+	for _, node := range nodes {
+		cpuList, err := getNumaNodeCpuCap(numaPath, node)
+		if err != nil {
+			return err
+		}
+		numa2CpuCap[node] = len(cpuList)
 
 		for _, cpu := range cpuList {
-			info.cpu2NUMA[cpu] = node
+			cpu2NUMA[cpu] = node
 		}
 	}
+
+	info.NUMANodes, info.NUMA2CpuCap, info.cpu2NUMA = nodes, numa2CpuCap, cpu2NUMA
+	return nil
 }
 
 func (info *CPUNumaInfo) numaAllocUpdate(cpuMngState string, enableGetCpuIDByPodResourceList bool) error {
+	e := info.exporter
 	var freeCPUList []int
+	var source string
 	var err error
 	if enableGetCpuIDByPodResourceList {
-		freeCPUList, info.podAllocations, err = GetFreeCPUListAndPodAllocationsByPodResources(info.cpu2NUMA)
+		start := e.clock.Now()
+		freeCPUList, info.podAllocations, err = GetFreeCPUListAndPodAllocationsByPodResources(e.podResources, info.cpu2NUMA)
+		result := podResourcesResultSuccess
+		if err != nil {
+			result = podResourcesResultError
+		}
+		e.metrics.podResourcesDuration.WithLabelValues(result).Observe(e.clock.Since(start).Seconds())
+		source = AllocationSourcePodResources
+		if err != nil {
+			// cpu_manager_state is less precise, it knows pods by UID only, but better than no update
+			podResourcesErr := err
+			freeCPUList, info.podAllocations, err = getFreeCPUListAndPodAllocationsByManagerState(cpuMngState)
+			source = AllocationSourceCPUManagerState
+			if err == nil {
+				e.setAllocationFallback(true, podResourcesErr)
+			}
+		} else {
+			e.setAllocationFallback(false, nil)
+		}
 	} else {
 		freeCPUList, info.podAllocations, err = getFreeCPUListAndPodAllocationsByManagerState(cpuMngState)
+		source = AllocationSourceCPUManagerState
 	}
 	if err != nil {
 		// Preserve the previous valid state by aborting the update on failure;
@@ -239,6 +308,9 @@ func (info *CPUNumaInfo) numaAllocUpdate(cpuMngState string, enableGetCpuIDByPod
 		// in the custom resource.
 		return err
 	}
+	e.stateMutex.Lock()
+	e.allocationSource = source
+	e.stateMutex.Unlock()
 
 	for _, cpuid := range freeCPUList {
 		numaID := info.cpu2numa(cpuid)
@@ -248,18 +320,59 @@ func (info *CPUNumaInfo) numaAllocUpdate(cpuMngState string, enableGetCpuIDByPod
 	return nil
 }
 
+// setAllocationFallback records whether the CPU allocations are read from cpu_manager_state
+// as the PodResources API failed with podResourcesErr, and reports the switch of the source
+func (e *Exporter) setAllocationFallback(fallback bool, podResourcesErr error) {
+	e.stateMutex.Lock()
+	defer e.stateMutex.Unlock()
+	if e.allocationFallback == fallback {
+		return
+	}
+
+	if fallback {
+		klog.Warningf("Failed to get CPU allocations from PodResources API, falling back to cpu_manager_state, err: %v", podResourcesErr)
+		e.recordNodeEvent(v1.EventTypeWarning, ReasonAllocationSourceFallback,
+			"CPU allocations are read from cpu_manager_state as the PodResources API failed: %v", podResourcesErr)
+	} else {
+		klog.Infof("Getting CPU allocations from PodResources API again")
+		e.recordNodeEvent(v1.EventTypeNormal, ReasonAllocationSourceRecovered, "CPU allocations are read from the PodResources API again")
+	}
+	e.allocationFallback = fallback
+}
+
 // Update returns the latest cpu numa info
-// if data is changed , return the latest , otherwise nil
+// if data is changed , return the latest , otherwise nil.
+// A field which cannot be read keeps its previous value and is marked stale, so a read error
+// is never published as a change of the topology.
 func (info *CPUNumaInfo) Update(opt *args.Argument) NumaInfo {
 	cpuNumaBasePath := filepath.Join(opt.DevicePath, "node")
-	newInfo := NewCPUNumaInfo()
-	newInfo.NUMANodes = getNumaOnline(filepath.Join(cpuNumaBasePath, "online"))
-	newInfo.numaCapUpdate(cpuNumaBasePath)
+	newInfo := NewCPUNumaInfo(info.exporter)
+	if err := newInfo.numaTopologyUpdate(cpuNumaBasePath); err != nil {
+		klog.Errorf("Failed to update NUMA topology, keeping the last known one: %v", err)
+		newInfo.NUMANodes, newInfo.NUMA2CpuCap, newInfo.cpu2NUMA = info.NUMANodes, info.NUMA2CpuCap, info.cpu2NUMA
+		newInfo.stale = append(newInfo.stale, newStaleField(resourceCPU, cpuFieldTopology, err))
+	}
 	if err := newInfo.numaAllocUpdate(opt.CPUMngState, opt.EnableGetCpuIDByPodResourceList); err != nil {
-		klog.Errorf("Failed to update NUMA allocation: %v", err)
-		return nil
+		klog.Errorf("Failed to update NUMA allocation, keeping the last known one: %v", err)
+		newInfo.NUMA2FreeCpus, newInfo.podAllocations = info.NUMA2FreeCpus, info.podAllocations
+		newInfo.stale = append(newInfo.stale, newStaleField(resourceCPU, cpuFieldAllocations, fmt.Errorf("update NUMA allocation failed: %v", err)))
 	}
-	newInfo.cpuDetail = newInfo.getAllCPUTopoInfo(opt.DevicePath)
+	var err error
+	newInfo.cpuDetail, err = newInfo.getAllCPUTopoInfo(opt.DevicePath, info.cpuDetail)
+	if err != nil {
+		klog.Errorf("Get cpu detail failed, keeping the last known detail of the failed cpus, err=<%v>", err)
+		newInfo.stale = append(newInfo.stale, newStaleField(resourceCPU, cpuFieldCPUDetail, err))
+	}
+	newInfo.cpuL3Cache = newInfo.getAllCPUL3CacheInfo(opt.DevicePath)
+	// the PCI device locality is only published to NFD, without it sysfs is not read and cannot make the topology stale
+	if opt.NFDFeaturesDir != "" {
+		newInfo.pciDevices, err = getPCIDeviceLocality(opt.PCIDevicePath, info.pciDevices)
+		if err != nil {
+			klog.Errorf("Get PCI device locality failed, keeping the last known locality of the failed devices, err=<%v>", err)
+			newInfo.stale = append(newInfo.stale, newStaleField(resourceCPU, cpuFieldPCIDevices, err))
+		}
+	}
+	info.exporter.recordCPUOnlineChanges(info.cpu2NUMA, newInfo.cpu2NUMA)
 	if !reflect.DeepEqual(newInfo, info) {
 		return newInfo
 	}
@@ -267,13 +380,54 @@ func (info *CPUNumaInfo) Update(opt *args.Argument) NumaInfo {
 	return nil
 }
 
-func (info *CPUNumaInfo) getAllCPUTopoInfo(devicePath string) map[int]v1alpha1.CPUInfo {
+// recordCPUOnlineChanges reports the cpus which went offline or online between the old and new cpu to NUMA node map,
+// nothing is reported on the first collection
+func (e *Exporter) recordCPUOnlineChanges(oldCPU2NUMA, newCPU2NUMA map[int]int) {
+	if len(oldCPU2NUMA) == 0 {
+		return
+	}
+
+	var offline, online []int
+	for cpu := range oldCPU2NUMA {
+		if _, ok := newCPU2NUMA[cpu]; !ok {
+			offline = append(offline, cpu)
+		}
+	}
+	for cpu := range newCPU2NUMA {
+		if _, ok := oldCPU2NUMA[cpu]; !ok {
+			online = append(online, cpu)
+		}
+	}
+
+	if len(offline) > 0 {
+		klog.Warningf("CPUs %s went offline", util.FormatCPUs(offline))
+		e.recordNodeEvent(v1.EventTypeWarning, ReasonCPUsOffline, "CPUs %s went offline", util.FormatCPUs(offline))
+	}
+	if len(online) > 0 {
+		klog.Infof("CPUs %s came online", util.FormatCPUs(online))
+		e.recordNodeEvent(v1.EventTypeNormal, ReasonCPUsOnline, "CPUs %s came online", util.FormatCPUs(online))
+	}
+}
+
+// getAllCPUTopoInfo returns the detail of every cpu, a cpu whose detail cannot be read keeps
+// its detail in previous, if any, and is reported in the returned error
+func (info *CPUNumaInfo) getAllCPUTopoInfo(devicePath string, previous map[int]v1alpha1.CPUInfo) (map[int]v1alpha1.CPUInfo, error) {
 	cpuTopoInfo := make(map[int]v1alpha1.CPUInfo)
+	// the error of the lowest failed cpu is reported, so it does not change with the map order
+	var failed []int
+	var firstFailed int
+	var firstErr error
 	for cpuID, numaID := range info.cpu2NUMA {
 		coreID, socketID, err := getCoreIDSocketIDForCpu(devicePath, cpuID)
 		if err != nil {
-			klog.Errorf("Get cpu detail failed, err=<%v>", err)
-			return nil
+			failed = append(failed, cpuID)
+			if firstErr == nil || cpuID < firstFailed {
+				firstFailed, firstErr = cpuID, err
+			}
+			if cpuInfo, ok := previous[cpuID]; ok {
+				cpuTopoInfo[cpuID] = cpuInfo
+			}
+			continue
 		}
 
 		cpuTopoInfo[cpuID] = v1alpha1.CPUInfo{
@@ -283,7 +437,46 @@ func (info *CPUNumaInfo) getAllCPUTopoInfo(devicePath string) map[int]v1alpha1.C
 		}
 	}
 
-	return cpuTopoInfo
+	if len(failed) > 0 {
+		return cpuTopoInfo, fmt.Errorf("get detail of cpus %s failed: %v", util.FormatCPUs(failed), firstErr)
+	}
+	return cpuTopoInfo, nil
+}
+
+func (info *CPUNumaInfo) getAllCPUL3CacheInfo(devicePath string) map[int]int {
+	cpuL3Cache := make(map[int]int)
+	for cpuID := range info.cpu2NUMA {
+		if cacheID, ok := getL3CacheIDForCpu(devicePath, cpuID); ok {
+			cpuL3Cache[cpuID] = cacheID
+		}
+	}
+
+	return cpuL3Cache
+}
+
+// getL3CacheIDForCpu returns the id of the L3 cache cpuID uses, or the first cpu sharing it
+// on kernels which do not report cache ids; false if the cpu has no L3 cache.
+func getL3CacheIDForCpu(devicePath string, cpuID int) (int, bool) {
+	cacheDirs, _ := filepath.Glob(filepath.Join(devicePath, fmt.Sprintf("cpu/cpu%d", cpuID), "cache", "index*"))
+	for _, cacheDir := range cacheDirs {
+		level, err := ioutil.ReadFile(filepath.Join(cacheDir, "level"))
+		if err != nil || strings.TrimSpace(string(level)) != "3" {
+			continue
+		}
+
+		if data, err := ioutil.ReadFile(filepath.Join(cacheDir, "id")); err == nil {
+			if cacheID, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				return cacheID, true
+			}
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(cacheDir, "shared_cpu_list")); err == nil {
+			if cpus, err := util.Parse(strings.TrimSpace(string(data))); err == nil && len(cpus) > 0 {
+				return cpus[0], true
+			}
+		}
+	}
+
+	return 0, false
 }
 
 func getCoreIDSocketIDForCpu(devicePath string, cpuID int) (coreID, socketID int, err error) {
@@ -343,8 +536,15 @@ func (info *CPUNumaInfo) GetResourceInfoMap() v1alpha1.ResourceInfo {
 	}
 }
 
-// GetResTopoDetail return the cpu capability topology info
-func (info *CPUNumaInfo) GetResTopoDetail() interface{} {
+// AddDetail adds the cpu capability topology, the L3 cache domains and the PCI device locality to detail
+func (info *CPUNumaInfo) AddDetail(detail *TopologyDetail) {
+	detail.CPUDetail = info.GetCPUDetail()
+	detail.CPUL3Cache = info.GetL3CacheDetail()
+	detail.PCIDevices = info.GetPCIDevices()
+}
+
+// GetCPUDetail return the cpu capability topology info
+func (info *CPUNumaInfo) GetCPUDetail() map[string]v1alpha1.CPUInfo {
 	allCPUTopoInfo := make(map[string]v1alpha1.CPUInfo)
 
 	for cpuID, cpuInfo := range info.cpuDetail {
@@ -354,7 +554,32 @@ func (info *CPUNumaInfo) GetResTopoDetail() interface{} {
 	return allCPUTopoInfo
 }
 
+// GetL3CacheDetail returns the L3 cache domain of every cpu which has one
+func (info *CPUNumaInfo) GetL3CacheDetail() map[string]int {
+	cpuL3Cache := make(map[string]int, len(info.cpuL3Cache))
+	for cpuID, cacheID := range info.cpuL3Cache {
+		cpuL3Cache[strconv.Itoa(cpuID)] = cacheID
+	}
+
+	return cpuL3Cache
+}
+
+// GetPCIDevices returns the NUMA locality of the PCI devices, by address
+func (info *CPUNumaInfo) GetPCIDevices() map[string]PCIDevice {
+	pciDevices := make(map[string]PCIDevice, len(info.pciDevices))
+	for address, device := range info.pciDevices {
+		pciDevices[address] = device
+	}
+
+	return pciDevices
+}
+
 // GetPodAllocations returns the pod allocation info
 func (info *CPUNumaInfo) GetPodAllocations() []v1alpha1.PodAllocation {
 	return info.podAllocations
 }
+
+// GetStaleFields returns the fields which could not be read in the last update
+func (info *CPUNumaInfo) GetStaleFields() []StaleField {
+	return info.stale
+}
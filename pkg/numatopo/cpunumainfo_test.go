@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"testing"
 
 	"google.golang.org/grpc"
@@ -177,12 +178,12 @@ func TestGetFreeCPUListAndPodAllocationsByManagerState(t *testing.T) {
 // numaAllocUpdate
 // ---------------------------------------------------------------------------
 
-// newInfoWithNUMA builds a CPUNumaInfo whose cpu2NUMA map is populated and
+// newInfoWithNUMA builds a CPUNumaInfo of e whose cpu2NUMA map is populated and
 // whose NUMA2FreeCpus map is initialized. NUMA2FreeCpus is seeded with the
 // given prior values so the "failure must not overwrite" contract can be
 // exercised.
-func newInfoWithNUMA(cpu2NUMA map[int]int, priorFreeCpus map[int][]int) *CPUNumaInfo {
-	info := NewCPUNumaInfo()
+func newInfoWithNUMA(e *Exporter, cpu2NUMA map[int]int, priorFreeCpus map[int][]int) *CPUNumaInfo {
+	info := NewCPUNumaInfo(e)
 	info.cpu2NUMA = cpu2NUMA
 	for numaID, cpus := range priorFreeCpus {
 		info.NUMA2FreeCpus[numaID] = append([]int(nil), cpus...)
@@ -202,7 +203,7 @@ func TestNumaAllocUpdate(t *testing.T) {
 			"uid-b": {"c0": "4"},
 		}))
 
-		info := newInfoWithNUMA(cpu2NUMA, nil)
+		info := newInfoWithNUMA(newTestExporter(t, "node-a"), cpu2NUMA, nil)
 		err := info.numaAllocUpdate(statePath, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -223,7 +224,7 @@ func TestNumaAllocUpdate(t *testing.T) {
 
 	t.Run("manager_state backend failure: does not overwrite prior NUMA2FreeCpus", func(t *testing.T) {
 		// Missing file -> backend returns error. Prior free state must survive.
-		info := newInfoWithNUMA(cpu2NUMA, map[int][]int{
+		info := newInfoWithNUMA(newTestExporter(t, "node-a"), cpu2NUMA, map[int][]int{
 			0: {0, 1},
 			1: {3, 4},
 		})
@@ -253,9 +254,9 @@ func TestNumaAllocUpdate(t *testing.T) {
 				}},
 			},
 		}
-		withClient(t, &fakePodResourcesClient{resp: resp})
+		e := newTestExporter(t, "node-a", WithPodResourcesClient(&fakePodResourcesClient{resp: resp}))
 
-		info := newInfoWithNUMA(cpu2NUMA, nil)
+		info := newInfoWithNUMA(e, cpu2NUMA, nil)
 		if err := info.numaAllocUpdate("", true); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -271,9 +272,9 @@ func TestNumaAllocUpdate(t *testing.T) {
 	})
 
 	t.Run("podresources backend failure: does not overwrite prior NUMA2FreeCpus", func(t *testing.T) {
-		withClient(t, &fakePodResourcesClient{err: errors.New("rpc broken")})
+		e := newTestExporter(t, "node-a", WithPodResourcesClient(&fakePodResourcesClient{err: errors.New("rpc broken")}))
 
-		info := newInfoWithNUMA(cpu2NUMA, map[int][]int{
+		info := newInfoWithNUMA(e, cpu2NUMA, map[int][]int{
 			0: {0, 1},
 			1: {3, 4},
 		})
@@ -290,8 +291,8 @@ func TestNumaAllocUpdate(t *testing.T) {
 	})
 
 	t.Run("podresources nil client: failure preserves prior state", func(t *testing.T) {
-		withClient(t, nil)
-		info := newInfoWithNUMA(cpu2NUMA, map[int][]int{0: {0}})
+		e := newTestExporter(t, "node-a", WithPodResourcesClient(nil))
+		info := newInfoWithNUMA(e, cpu2NUMA, map[int][]int{0: {0}})
 		if err := info.numaAllocUpdate("", true); err == nil {
 			t.Fatalf("expected error when client is nil")
 		}
@@ -303,9 +304,9 @@ func TestNumaAllocUpdate(t *testing.T) {
 	t.Run("empty freeCPUList clears NUMA2FreeCpus bucket when no prior state", func(t *testing.T) {
 		// Manager-state with defaultCPUSet that parses to nothing is hard (Parse is
 		// lenient), so use the podresources path with no pods: all CPUs free.
-		withClient(t, &fakePodResourcesClient{resp: &podresv1.ListPodResourcesResponse{}})
+		e := newTestExporter(t, "node-a", WithPodResourcesClient(&fakePodResourcesClient{resp: &podresv1.ListPodResourcesResponse{}}))
 
-		info := newInfoWithNUMA(cpu2NUMA, nil)
+		info := newInfoWithNUMA(e, cpu2NUMA, nil)
 		if err := info.numaAllocUpdate("", true); err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -343,15 +344,6 @@ func (f *fakePodResourcesClient) Get(ctx context.Context, _ *podresv1.GetPodReso
 	return nil, errors.New("not implemented")
 }
 
-// withClient swaps the package-level client var for the duration of a test and
-// restores the previous value on cleanup.
-func withClient(t *testing.T, c podresv1.PodResourcesListerClient) {
-	t.Helper()
-	prev := client
-	client = c
-	t.Cleanup(func() { client = prev })
-}
-
 // Sanity guard: the fake must satisfy the PodResourcesListerClient interface.
 var _ podresv1.PodResourcesListerClient = (*fakePodResourcesClient)(nil)
 
@@ -364,24 +356,21 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 	cpu2NUMA := map[int]int{0: 0, 1: 0, 2: 0, 3: 0, 4: 1, 5: 1, 6: 1, 7: 1}
 
 	t.Run("nil client returns error", func(t *testing.T) {
-		withClient(t, nil)
-		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(nil, cpu2NUMA)
 		if err == nil {
 			t.Fatalf("expected error when client is nil")
 		}
 	})
 
 	t.Run("List error propagates", func(t *testing.T) {
-		withClient(t, &fakePodResourcesClient{err: errors.New("rpc broken")})
-		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{err: errors.New("rpc broken")}, cpu2NUMA)
 		if err == nil {
 			t.Fatalf("expected error when List fails")
 		}
 	})
 
 	t.Run("nil response returns error", func(t *testing.T) {
-		withClient(t, &fakePodResourcesClient{resp: nil})
-		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		_, _, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{resp: nil}, cpu2NUMA)
 		if err == nil {
 			t.Fatalf("expected error when response is nil")
 		}
@@ -409,9 +398,7 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 				},
 			},
 		}
-		withClient(t, &fakePodResourcesClient{resp: resp})
-
-		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{resp: resp}, cpu2NUMA)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -468,9 +455,7 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 				},
 			},
 		}
-		withClient(t, &fakePodResourcesClient{resp: resp})
-
-		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{resp: resp}, cpu2NUMA)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -493,9 +478,7 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 				}},
 			},
 		}
-		withClient(t, &fakePodResourcesClient{resp: resp})
-
-		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(map[int]int{})
+		freeCpus, podAllocs, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{resp: resp}, map[int]int{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -516,9 +499,7 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 				}},
 			},
 		}
-		withClient(t, &fakePodResourcesClient{resp: resp})
-
-		freeCpus, _, err := GetFreeCPUListAndPodAllocationsByPodResources(cpu2NUMA)
+		freeCpus, _, err := GetFreeCPUListAndPodAllocationsByPodResources(&fakePodResourcesClient{resp: resp}, cpu2NUMA)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -530,3 +511,31 @@ func TestGetFreeCPUListAndPodAllocationsByPodResources(t *testing.T) {
 
 // Ensure sort import stays referenced if future helpers use it directly.
 var _ = sort.Slice
+
+func TestGetL3CacheIDForCpu(t *testing.T) {
+	devicePath := t.TempDir()
+	writeCache := func(cpu int, index string, files map[string]string) {
+		dir := filepath.Join(devicePath, "cpu", "cpu"+strconv.Itoa(cpu), "cache", index)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0o600); err != nil {
+				t.Fatalf("write %s: %v", name, err)
+			}
+		}
+	}
+	// cpu 0 reports the cache id, cpu 1 only the cpus sharing it, cpu 2 has no L3 cache
+	writeCache(0, "index2", map[string]string{"level": "2", "id": "0"})
+	writeCache(0, "index3", map[string]string{"level": "3", "id": "4"})
+	writeCache(1, "index3", map[string]string{"level": "3", "shared_cpu_list": "8-15"})
+	writeCache(2, "index2", map[string]string{"level": "2", "id": "2"})
+
+	info := NewCPUNumaInfo(newTestExporter(t, "node-a"))
+	info.cpu2NUMA = map[int]int{0: 0, 1: 0, 2: 0}
+	got := info.getAllCPUL3CacheInfo(devicePath)
+	expected := map[int]int{0: 4, 1: 8}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
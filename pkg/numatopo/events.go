@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const componentName = "resource-exporter"
+
+// the reasons of the Events about transitions of the local state
+const (
+	// ReasonPolicyChanged is reported when the kubelet CPU or topology manager policy or scope changes
+	ReasonPolicyChanged = "TopologyPolicyChanged"
+	// ReasonReservationChanged is reported when the reserved resources change
+	ReasonReservationChanged = "ResourceReservationChanged"
+	// ReasonCPUsOffline is reported when CPUs disappear from the NUMA nodes
+	ReasonCPUsOffline = "CPUsOffline"
+	// ReasonCPUsOnline is reported when CPUs appear on the NUMA nodes
+	ReasonCPUsOnline = "CPUsOnline"
+	// ReasonAllocationSourceFallback is reported when the PodResources API fails and the CPU allocations
+	// are read from cpu_manager_state instead
+	ReasonAllocationSourceFallback = "AllocationSourceFallback"
+	// ReasonAllocationSourceRecovered is reported when the PodResources API works again after a fallback
+	ReasonAllocationSourceRecovered = "AllocationSourceRecovered"
+	// ReasonTopologyAffinityError is reported when kubelet rejects a pod of the node with TopologyAffinityError
+	ReasonTopologyAffinityError = "TopologyAffinityError"
+	// ReasonPublishFailed is reported when publishing failed publishFailureEventThreshold times in a row
+	ReasonPublishFailed = "PublishFailed"
+	// ReasonPublishRecovered is reported when publishing succeeds after ReasonPublishFailed
+	ReasonPublishRecovered = "PublishRecovered"
+	// ReasonRegressionHeld is reported when an implausible regression of the topology is held back
+	ReasonRegressionHeld = "TopologyRegressionHeld"
+	// ReasonRegressionPublished is reported when a held back regression persisted and is published
+	ReasonRegressionPublished = "TopologyRegressionPublished"
+	// ReasonKubeletConfigMismatch is reported when the kubelet config file starts to disagree
+	// with the effective configuration read from /configz, or disagrees differently
+	ReasonKubeletConfigMismatch = "KubeletConfigMismatch"
+	// ReasonConfigReloaded is reported when a changed configuration file is applied
+	ReasonConfigReloaded = "ConfigReloaded"
+	// ReasonConfigReloadFailed is reported when the configuration file cannot be reloaded,
+	// the previous configuration is kept
+	ReasonConfigReloadFailed = "ConfigReloadFailed"
+)
+
+// Events are rate limited per reason, so a flapping condition does not hide the others
+const (
+	eventBurst = 5
+	eventQPS   = 1. / 300
+)
+
+// startEventRecorder starts sending Kubernetes Events about the node with the kube client,
+// the returned broadcaster is to be shut down once the exporter stops
+func (e *Exporter) startEventRecorder() record.EventBroadcaster {
+	broadcaster := record.NewBroadcaster(record.WithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize:   eventBurst,
+		QPS:         eventQPS,
+		SpamKeyFunc: eventSpamKey,
+	}))
+	broadcaster.StartStructuredLogging(4)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: e.kubeClient.CoreV1().Events("")})
+	e.recorder = broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: componentName, Host: e.nodeName})
+	return broadcaster
+}
+
+// recordNodeEvent emits an Event on the Node, it is a no-op if the recorder is not initialized
+func (e *Exporter) recordNodeEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if e.recorder == nil {
+		return
+	}
+	e.recorder.Eventf(e.nodeRef, eventType, reason, messageFmt, args...)
+}
+
+// eventSpamKey groups Events for rate limiting by the object and reason, instead of by the object only
+func eventSpamKey(event *v1.Event) string {
+	return strings.Join([]string{
+		event.Source.Component,
+		event.Source.Host,
+		event.InvolvedObject.Kind,
+		event.InvolvedObject.Namespace,
+		event.InvolvedObject.Name,
+		string(event.InvolvedObject.UID),
+		event.InvolvedObject.APIVersion,
+		event.Type,
+		event.Reason,
+	}, "")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// newExporterWithFakeRecorder creates an Exporter whose Events are kept for recordedEvents
+func newExporterWithFakeRecorder(t *testing.T, options ...Option) (*Exporter, *record.FakeRecorder) {
+	fake := record.NewFakeRecorder(10)
+	return newTestExporter(t, "node-a", append(options, WithEventRecorder(fake))...), fake
+}
+
+// recordedEvents returns the Events recorded so far
+func recordedEvents(fake *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-fake.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventSpamKeyPerReason(t *testing.T) {
+	event := &v1.Event{
+		Source:         v1.EventSource{Component: componentName, Host: "node-a"},
+		InvolvedObject: v1.ObjectReference{Kind: "Node", Name: "node-a"},
+		Type:           v1.EventTypeWarning,
+		Reason:         ReasonCPUsOffline,
+	}
+	other := event.DeepCopy()
+	other.Reason = ReasonPublishFailed
+	if eventSpamKey(event) == eventSpamKey(other) {
+		t.Fatalf("expected Events of different reasons to be rate limited separately")
+	}
+
+	other = event.DeepCopy()
+	other.Message = "another message"
+	if eventSpamKey(event) != eventSpamKey(other) {
+		t.Fatalf("expected Events of the same reason to share the rate limit")
+	}
+}
+
+func TestRecordCPUOnlineChanges(t *testing.T) {
+	e, fake := newExporterWithFakeRecorder(t)
+
+	e.recordCPUOnlineChanges(map[int]int{}, map[int]int{0: 0, 1: 0})
+	if events := recordedEvents(fake); len(events) != 0 {
+		t.Fatalf("expected no Event on the first collection, got %v", events)
+	}
+
+	e.recordCPUOnlineChanges(map[int]int{0: 0, 1: 0, 2: 1, 3: 1}, map[int]int{0: 0, 1: 0, 4: 1})
+	events := recordedEvents(fake)
+	if len(events) != 2 ||
+		!strings.HasPrefix(events[0], v1.EventTypeWarning+" "+ReasonCPUsOffline+" CPUs 2-3") ||
+		!strings.HasPrefix(events[1], v1.EventTypeNormal+" "+ReasonCPUsOnline+" CPUs 4") {
+		t.Fatalf("unexpected Events %v", events)
+	}
+}
+
+func TestTryUpdatingResourceReservationEvents(t *testing.T) {
+	e, fake := newExporterWithFakeRecorder(t)
+	e.getNode = func() (*v1.Node, error) { return nil, fmt.Errorf("forbidden") }
+	klConfig := &kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static", TopologyManagerPolicy: "none"}
+
+	e.TryUpdatingResourceReservation(klConfig, map[string]string{string(v1.ResourceCPU): "1"})
+	if events := recordedEvents(fake); len(events) != 0 {
+		t.Fatalf("expected no Event on the first collection, got %v", events)
+	}
+
+	klConfig.TopologyManagerPolicy = "single-numa-node"
+	e.TryUpdatingResourceReservation(klConfig, map[string]string{string(v1.ResourceCPU): "2"})
+	events := recordedEvents(fake)
+	if len(events) != 2 ||
+		!strings.HasPrefix(events[0], v1.EventTypeNormal+" "+ReasonPolicyChanged+" "+string(nodeinfov1alpha1.TopologyManagerPolicy)+" changed") ||
+		!strings.HasPrefix(events[1], v1.EventTypeNormal+" "+ReasonReservationChanged) {
+		t.Fatalf("unexpected Events %v", events)
+	}
+
+	e.TryUpdatingResourceReservation(klConfig, map[string]string{string(v1.ResourceCPU): "2"})
+	if events = recordedEvents(fake); len(events) != 0 {
+		t.Fatalf("expected no Event without a change, got %v", events)
+	}
+}
@@ -0,0 +1,473 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
+	podresv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+	"k8s.io/utils/clock"
+
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+
+	"volcano.sh/resource-exporter/pkg/args"
+	"volcano.sh/resource-exporter/pkg/features"
+)
+
+// Exporter collects the NUMA topology, the kubelet configuration and the CPU allocations of one node
+// and publishes them. All the state is kept per Exporter, so several of them can run in one process,
+// e.g. embedded in a node agent or in tests.
+type Exporter struct {
+	opt      *args.Argument
+	nodeName string
+	clock    clock.WithTickerAndDelayedExecution
+
+	// startupOpt are the options the Exporter started with, before the paths were resolved
+	startupOpt *args.Argument
+	// configLoader reloads the options, nil if they are not reloaded
+	configLoader *args.ConfigLoader
+	// reloads schedules reloading the options in the refresh loop
+	reloads chan struct{}
+	// restartRequired are the options which changed since the start but are only applied by a restart
+	restartRequired []string
+	// overrides are the changes of the Argument made by Options, they are applied to every reloaded one too
+	overrides []func(opt *args.Argument)
+
+	restConfig     *rest.Config
+	kubeClient     kubernetes.Interface
+	nodeInfoClient versioned.Interface
+	dynamicClient  dynamic.Interface
+	podResources   podresv1.PodResourcesListerClient
+
+	recorder record.EventRecorder
+	nodeRef  *v1.ObjectReference
+	// nodeOwner is the Node the Numatopology belongs to, nil if it could not be read.
+	// It is read once: a Node deleted and registered again under the same name gets a new UID,
+	// but the pods bound to the old one, the exporter included, are deleted with it.
+	nodeOwner *metav1.OwnerReference
+
+	// featureGate enables the alpha and beta providers
+	featureGate featuregate.FeatureGate
+	// factories are the providers which can be selected with --providers, by name
+	factories map[string]providerFactory
+
+	// providerMutex guards providers and updating, the providers are read while they are updated
+	providerMutex sync.RWMutex
+	// providers are the last good snapshot of the registered NumaInfo by name
+	providers map[string]NumaInfo
+	// updating are the names of the providers whose Update is running, it may outlive its timeout
+	updating map[string]bool
+	// providerStale are the providers whose last update timed out
+	providerStale []StaleField
+
+	config *kubeletConfig
+	// getNode returns the Node the exporter runs on, it is nil unless node status reservation is enabled
+	getNode    func() (*v1.Node, error)
+	getConfigz configzGetter
+	// configzDiffs are the fields the kubelet config file last disagreed with /configz on, so they are reported once
+	configzDiffs []string
+
+	configWarnings []ConfigWarning
+	// conditions are the current conditions, in the order they are published
+	conditions []NumatopoCondition
+	// stale are the parts of the topology published with their last-known-good value, sorted
+	stale []StaleField
+
+	// stateMutex guards the state below, which the cpu provider sets while it is updated in parallel
+	stateMutex sync.Mutex
+	// allocationFallback is true while the CPU allocations are read from cpu_manager_state as the PodResources API fails
+	allocationFallback bool
+	// allocationSource is where the last CPU allocations were read from
+	allocationSource string
+
+	// metrics are the Prometheus metrics of the Exporter
+	metrics *exporterMetrics
+	// guard holds back implausible regressions of the topology before they are written
+	guard *regressionGuard
+	// lastGeneration is the generation of the last successful write
+	lastGeneration int64
+	// topologyAffinityErrors is the number of pods of the node rejected with TopologyAffinityError
+	topologyAffinityErrors uint64
+}
+
+// Option configures an Exporter
+type Option func(*Exporter)
+
+// WithRestConfig builds the clients not given by the other options from config,
+// it is also used to query the kubelet /configz endpoint
+func WithRestConfig(config *rest.Config) Option {
+	return func(e *Exporter) {
+		e.restConfig = config
+	}
+}
+
+// WithKubeClient sets the client used for the Node, its pods and Events
+func WithKubeClient(client kubernetes.Interface) Option {
+	return func(e *Exporter) {
+		e.kubeClient = client
+	}
+}
+
+// WithNumatopoClient sets the client the Numatopology is written with
+func WithNumatopoClient(client versioned.Interface) Option {
+	return func(e *Exporter) {
+		e.nodeInfoClient = client
+	}
+}
+
+// WithDynamicClient sets the client the NodeResourceTopology is written with
+func WithDynamicClient(client dynamic.Interface) Option {
+	return func(e *Exporter) {
+		e.dynamicClient = client
+	}
+}
+
+// WithPodResourcesClient sets the client the CPU allocations are listed with,
+// instead of connecting to the kubelet pod-resources socket
+func WithPodResourcesClient(client podresv1.PodResourcesListerClient) Option {
+	return func(e *Exporter) {
+		e.podResources = client
+	}
+}
+
+// WithHostRoot sets the path the host filesystem is mounted at, overriding the Argument.
+// The paths which are not set explicitly are derived under it.
+func WithHostRoot(root string) Option {
+	return func(e *Exporter) {
+		e.overrides = append(e.overrides, func(opt *args.Argument) {
+			opt.HostRoot = root
+		})
+	}
+}
+
+// WithClock sets the clock used for the heartbeats of the conditions, the periodic refresh
+// and the debouncing of file and pod changes
+func WithClock(clock clock.WithTickerAndDelayedExecution) Option {
+	return func(e *Exporter) {
+		e.clock = clock
+	}
+}
+
+// WithEventRecorder sets the recorder of the Events about the node,
+// instead of starting one from the kube client
+func WithEventRecorder(recorder record.EventRecorder) Option {
+	return func(e *Exporter) {
+		e.recorder = recorder
+	}
+}
+
+// WithFeatureGate sets the feature gate the alpha and beta providers are enabled with,
+// instead of the one set with --feature-gates
+func WithFeatureGate(gate featuregate.FeatureGate) Option {
+	return func(e *Exporter) {
+		e.featureGate = gate
+	}
+}
+
+// WithProviderFactory makes the provider created by factory selectable by name in --providers,
+// replacing the built-in provider of the same name if any
+func WithProviderFactory(name string, factory ProviderFactory) Option {
+	return func(e *Exporter) {
+		e.factories[name] = providerFactory{create: factory}
+	}
+}
+
+// NewExporter creates an Exporter for the node opt.NodeName. The paths of opt which are not set
+// are resolved as the exporter command does; opt itself is not modified.
+func NewExporter(opt *args.Argument, options ...Option) (*Exporter, error) {
+	if opt.NodeName == "" {
+		return nil, fmt.Errorf("node name is required")
+	}
+
+	o := opt.DeepCopy()
+	e := &Exporter{
+		opt:         o,
+		nodeName:    o.NodeName,
+		clock:       clock.RealClock{},
+		featureGate: features.DefaultFeatureGate,
+		factories:   make(map[string]providerFactory, len(providerFactories)),
+		providers:   make(map[string]NumaInfo),
+		updating:    make(map[string]bool),
+		reloads:     make(chan struct{}, 1),
+		config:      newKubeletConfig(),
+		// kubelet uses the node name as the UID of node events, so ours are listed alongside them
+		nodeRef: &v1.ObjectReference{
+			Kind: "Node",
+			Name: o.NodeName,
+			UID:  types.UID(o.NodeName),
+		},
+	}
+	for name, factory := range providerFactories {
+		e.factories[name] = factory
+	}
+	for _, option := range options {
+		option(e)
+	}
+	for _, override := range e.overrides {
+		override(e.opt)
+	}
+	e.startupOpt = e.opt.DeepCopy()
+	e.opt.ResolveKubeletPaths()
+	e.guard = newRegressionGuard(e)
+	e.metrics = newExporterMetrics(e)
+
+	if err := e.buildClients(); err != nil {
+		return nil, err
+	}
+
+	if err := e.registerProviders(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// buildClients builds the clients which were not given from the rest config, if any
+func (e *Exporter) buildClients() error {
+	if e.restConfig == nil {
+		return nil
+	}
+
+	var err error
+	if e.kubeClient == nil {
+		if e.kubeClient, err = kubernetes.NewForConfig(e.restConfig); err != nil {
+			return fmt.Errorf("build kube client failed, err: %v", err)
+		}
+	}
+	if e.nodeInfoClient == nil {
+		if e.nodeInfoClient, err = versioned.NewForConfig(e.restConfig); err != nil {
+			return fmt.Errorf("build Numatopology client failed, err: %v", err)
+		}
+	}
+	if e.dynamicClient == nil && e.opt.PublishNRT {
+		if e.dynamicClient, err = dynamic.NewForConfig(e.restConfig); err != nil {
+			return fmt.Errorf("build dynamic client failed, err: %v", err)
+		}
+	}
+	return nil
+}
+
+// NodeName returns the name of the node the Exporter publishes the topology of
+func (e *Exporter) NodeName() string {
+	return e.nodeName
+}
+
+// Run publishes the topology of the node until ctx is done. The local state is collected again
+// every --check-period, or right after the changes watched as configured and every --resync-period.
+// With a config loader, the reloadable options are applied when the configuration file changes.
+func (e *Exporter) Run(ctx context.Context) error {
+	if e.nodeInfoClient == nil {
+		return fmt.Errorf("no Numatopology client, use WithNumatopoClient or WithRestConfig")
+	}
+	needKubeClient := e.opt.ReservationFromNode || e.opt.PublishSlices || e.opt.PublishNodeLabels || e.opt.WatchPods ||
+		(e.opt.KubeletConfigSource == args.KubeletConfigSourceConfigz && e.opt.KubeletConfigzURL == "")
+	if e.kubeClient == nil && needKubeClient {
+		return fmt.Errorf("no kube client, use WithKubeClient or WithRestConfig")
+	}
+	if e.dynamicClient == nil && e.opt.PublishNRT {
+		return fmt.Errorf("no dynamic client for --publish-nrt, use WithDynamicClient or WithRestConfig")
+	}
+	stopCh := ctx.Done()
+
+	if address := e.opt.MetricsBindAddress; address != "" {
+		if err := e.serveMetrics(ctx, address); err != nil {
+			return fmt.Errorf("failed to serve metrics on %s: %v", address, err)
+		}
+	}
+
+	if e.recorder == nil && e.kubeClient != nil {
+		broadcaster := e.startEventRecorder()
+		defer broadcaster.Shutdown()
+	}
+
+	if e.kubeClient != nil {
+		if err := e.initNodeOwner(); err != nil {
+			// the Numatopology is still published, it is left to the orphan collector once the Node is gone
+			klog.Errorf("Failed to get Node %s, Numatopology will have no owner: %v", e.nodeName, err)
+		}
+	}
+
+	if e.opt.KubeletConfigSource == args.KubeletConfigSourceConfigz && e.getConfigz == nil {
+		if err := e.initKubeletConfigzClient(); err != nil {
+			return fmt.Errorf("failed to init kubelet configz client: %v", err)
+		}
+	}
+
+	if e.opt.EnableGetCpuIDByPodResourceList && e.podResources == nil {
+		conn, err := e.connectPodResources()
+		if err != nil {
+			// Fall back to the cpu_manager_state file-based method instead of
+			// leaving the client nil: a nil client would make every NUMA
+			// allocation update fail, so the node topology would silently stop
+			// being reported. Degrading keeps the daemon reporting, just via the
+			// legacy (less reliable) source.
+			klog.Errorf("Failed to init podresources client, falling back to cpu_manager_state method: %v", err)
+			e.opt.EnableGetCpuIDByPodResourceList = false
+		} else {
+			defer conn.Close()
+		}
+	}
+
+	// The Node is watched rather than read on every refresh, for its reservation and its labels
+	var nodeCache *NodeCache
+	if e.opt.ReservationFromNode || e.opt.PublishNodeLabels {
+		nodeCache = NewNodeCache(e.kubeClient, e.nodeName)
+		nodeCache.Start(stopCh)
+		if !nodeCache.WaitForCacheSync(stopCh) {
+			return fmt.Errorf("failed to sync Node informer cache")
+		}
+	}
+	if e.opt.ReservationFromNode {
+		e.UseNodeStatusReservation(nodeCache)
+	}
+
+	// Initialize Numatopology informer cache
+	// This uses list-watch mechanism instead of polling
+	numaCache := NewNumatopoCache(e.nodeInfoClient, e.nodeName)
+
+	// Writes of the Numatopology are queued, so failed ones are retried until the object matches.
+	// Changes seen by the informer are reconciled as well, so edits by others are reverted.
+	publisher := NewPublisher(e, numaCache)
+	if err := numaCache.AddEventHandler(publisher.OnNumatopoChange); err != nil {
+		return fmt.Errorf("failed to watch Numatopology changes: %v", err)
+	}
+	if e.opt.PublishNRT {
+		publisher.AddSink(NewNRTSink(e.dynamicClient, e.nodeName))
+	}
+	if e.opt.PublishSlices {
+		publisher.AddSink(NewResourceSliceSink(e.kubeClient, e.nodeName, e.opt.DRADriverName))
+	}
+	if e.opt.PublishNodeLabels {
+		labelSink := NewNodeLabelSink(e.kubeClient, e.nodeName)
+		labelSink.UseNodeCache(nodeCache)
+		publisher.AddSink(labelSink)
+	}
+	if e.opt.NFDFeaturesDir != "" {
+		publisher.AddSink(NewNFDSink(e.opt.NFDFeaturesDir))
+	}
+	go publisher.Run(ctx)
+
+	// Start the informer (non-blocking, runs in background goroutine)
+	numaCache.Start(stopCh)
+
+	// Wait for informer cache to sync (blocking until initial list is complete)
+	if !numaCache.WaitForCacheSync(stopCh) {
+		return fmt.Errorf("failed to sync Numatopology informer cache")
+	}
+	klog.V(2).Infof("Numatopology informer cache synced successfully")
+
+	refresh := func() {
+		// Check local resource changes, including kubelet config, node numa topologies and pod resource allocations
+		isChg := e.NodeInfoRefresh()
+		klog.V(4).Infof("Local resource changes within the interval: %v", isChg)
+
+		// The local state is compared with the Numatopology in the informer cache before writing,
+		// so nothing is written if they match, e.g. after a restart
+		publisher.Enqueue()
+
+		if status := publisher.Status(); status.Pending && status.Failures > 0 {
+			klog.Warningf("Numatopology is not published yet after %d failed attempt(s), last err=%v", status.Failures, status.LastError)
+		}
+	}
+
+	// Changes of the kubelet state and configuration trigger a refresh right away,
+	// the periodic refresh is then only a safety resync
+	watching := false
+	period := func() time.Duration {
+		if watching {
+			return e.opt.ResyncPeriod
+		}
+		return e.opt.CheckInterval
+	}
+	triggers := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case triggers <- struct{}{}:
+		default:
+		}
+	}
+	if e.opt.WatchFiles {
+		watcher, err := NewFileWatcher(e.opt, e.clock)
+		if err != nil {
+			klog.Errorf("Failed to watch files, refreshing every %v instead, err: %v", period(), err)
+		} else {
+			watching = true
+			go watcher.Run(ctx, trigger)
+		}
+	}
+
+	// Pods starting and terminating trigger a refresh of the allocations, which the PodResources API cannot be watched for
+	if e.opt.WatchPods {
+		podCache := NewPodCache(e.kubeClient, e.nodeName, e.clock)
+		if err := podCache.AddAllocationHandler(trigger); err != nil {
+			return fmt.Errorf("failed to watch Pod changes: %v", err)
+		}
+		// A pod rejected with TopologyAffinityError was scheduled from stale NUMA data, so the
+		// local state is collected again and the Numatopology is checked against it right away
+		err := podCache.AddTopologyAffinityErrorHandler(func(pod *v1.Pod) {
+			e.recordTopologyAffinityError(pod)
+			trigger()
+			publisher.Resync()
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch Pod rejections: %v", err)
+		}
+		podCache.Start(stopCh)
+		if !podCache.WaitForCacheSync(stopCh) {
+			return fmt.Errorf("failed to sync Pod informer cache")
+		}
+	}
+
+	// The configuration file is reloaded when it changes and on Reload, e.g. on SIGHUP
+	if e.configLoader != nil && e.configLoader.Path() != "" {
+		if err := e.watchConfigFile(ctx, e.configLoader.Path()); err != nil {
+			klog.Errorf("Failed to watch the configuration file, it is only reloaded on SIGHUP, err: %v", err)
+		}
+	}
+
+	ticker := e.clock.NewTicker(period())
+	defer func() { ticker.Stop() }()
+	for {
+		refresh()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+			e.guard.countCycle()
+		case <-triggers:
+		case <-e.reloads:
+			previous := period()
+			e.reloadConfig()
+			if current := period(); current != previous {
+				klog.V(2).Infof("Refresh period changed from %v to %v", previous, current)
+				ticker.Stop()
+				ticker = e.clock.NewTicker(current)
+			}
+		}
+	}
+}
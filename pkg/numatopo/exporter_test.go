@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+// newTestExporter creates an Exporter for nodeName whose host filesystem is an empty temporary directory
+func newTestExporter(t *testing.T, nodeName string, options ...Option) *Exporter {
+	t.Helper()
+	opt := args.NewArgument()
+	opt.NodeName = nodeName
+	e, err := NewExporter(opt, append([]Option{WithHostRoot(t.TempDir())}, options...)...)
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+	return e
+}
+
+// writeHostFile writes content to path under the host root, creating its directories
+func writeHostFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	path = filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content+"\n"), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// writeHostTopology creates the sysfs NUMA and cpu topology, every NUMA node is a socket of numaNodes[i] cpus,
+// and a kubelet root directory with the given free cpus in cpu_manager_state
+func writeHostTopology(t *testing.T, root string, numaNodes []int, freeCPUs string) {
+	t.Helper()
+	cpu := 0
+	for node, cpus := range numaNodes {
+		first := cpu
+		for ; cpu < first+cpus; cpu++ {
+			topology := filepath.Join("sys/devices/system/cpu", "cpu"+strconv.Itoa(cpu), "topology")
+			writeHostFile(t, root, filepath.Join(topology, "core_id"), strconv.Itoa(cpu-first))
+			writeHostFile(t, root, filepath.Join(topology, "physical_package_id"), strconv.Itoa(node))
+		}
+		writeHostFile(t, root, fmt.Sprintf("sys/devices/system/node/node%d/cpulist", node), fmt.Sprintf("%d-%d", first, cpu-1))
+	}
+	writeHostFile(t, root, "sys/devices/system/node/online", fmt.Sprintf("0-%d", len(numaNodes)-1))
+	writeHostFile(t, root, "var/lib/kubelet/config.yaml", "cpuManagerPolicy: static\ntopologyManagerPolicy: single-numa-node")
+
+	statePath := filepath.Join(root, "var/lib/kubelet/cpu_manager_state")
+	writeCheckpointFile(t, statePath, newCheckpoint(freeCPUs, nil))
+}
+
+func TestExportersAreIndependent(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rootA, rootB := t.TempDir(), t.TempDir()
+	writeHostTopology(t, rootA, []int{2, 2}, "0-3")
+	writeHostTopology(t, rootB, []int{4}, "1-3")
+
+	opt := args.NewArgument()
+	opt.NodeName = "node-a"
+	clientA := fake.NewSimpleClientset()
+	exporterA, err := NewExporter(opt, WithHostRoot(rootA), WithNumatopoClient(clientA), WithClock(clocktesting.NewFakeClock(start)))
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+	opt.NodeName = "node-b"
+	clientB := fake.NewSimpleClientset()
+	exporterB, err := NewExporter(opt, WithHostRoot(rootB), WithNumatopoClient(clientB), WithClock(clocktesting.NewFakeClock(start.Add(time.Hour))))
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+
+	for _, e := range []*Exporter{exporterA, exporterB} {
+		if !e.NodeInfoRefresh() {
+			t.Fatalf("expected the first refresh of %s to change the state", e.NodeName())
+		}
+		if err := e.CreateOrUpdateNumatopo(nil); err != nil {
+			t.Fatalf("CreateOrUpdateNumatopo of %s failed: %v", e.NodeName(), err)
+		}
+	}
+
+	if got := exporterA.GetAllResAllocatableInfo()[resourceCPU]; got.Capacity != 4 || got.Allocatable != "0-3" {
+		t.Fatalf("unexpected cpu resource of node-a: %+v", got)
+	}
+	if got := exporterB.GetAllResAllocatableInfo()[resourceCPU]; got.Capacity != 4 || got.Allocatable != "1-3" {
+		t.Fatalf("unexpected cpu resource of node-b: %+v", got)
+	}
+	if summary := summarizeTopology(exporterA.Snapshot()); summary.NUMANodes != 2 || summary.Sockets != 2 {
+		t.Fatalf("expected 2 NUMA nodes and sockets on node-a, got %+v", summary)
+	}
+	if policy := exporterB.GetPolicy()[nodeinfov1alpha1.CPUManagerPolicy]; policy != "static" {
+		t.Fatalf("expected the cpu manager policy of node-b from its kubelet config, got %q", policy)
+	}
+	if heartbeat := exporterB.GetConditions()[0].LastHeartbeatTime; !heartbeat.Time.Equal(start.Add(time.Hour)) {
+		t.Fatalf("expected the heartbeat from the clock of node-b, got %v", heartbeat)
+	}
+
+	// every Exporter writes the Numatopology of its own node with its own client only
+	if _, err := clientA.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), "node-a", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the Numatopology of node-a: %v", err)
+	}
+	if _, err := clientA.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), "node-b", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected no Numatopology of node-b written with the client of node-a")
+	}
+	numaB, err := clientB.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), "node-b", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the Numatopology of node-b: %v", err)
+	}
+	if got := numaB.Spec.NumaResMap[resourceCPU].Allocatable; got != "1-3" {
+		t.Fatalf("unexpected allocatable cpus of node-b: %q", got)
+	}
+}
+
+func TestNewExporterRequiresNodeName(t *testing.T) {
+	if _, err := NewExporter(args.NewArgument()); err == nil {
+		t.Fatalf("expected an error without node name")
+	}
+}
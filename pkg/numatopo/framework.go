@@ -17,67 +17,217 @@ limitations under the License.
 package numatopo
 
 import (
-	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/klog/v2"
 
-	"volcano.sh/resource-exporter/pkg/args"
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
 )
 
-var numaMap = map[string]NumaInfo{}
+// ProviderFactory creates a provider collecting for the Exporter e
+type ProviderFactory func(e *Exporter) NumaInfo
+
+// providerFactory is a provider which can be selected with --providers
+type providerFactory struct {
+	create ProviderFactory
+	// feature is the feature gate the provider requires, empty if it is always available
+	feature featuregate.Feature
+}
+
+// providerFactories are the built-in providers by name
+var providerFactories = map[string]providerFactory{
+	resourceCPU: {create: func(e *Exporter) NumaInfo { return NewCPUNumaInfo(e) }},
+}
+
+// registerProviders registers the providers selected with --providers which are not registered yet
+// and removes the others, so it is called again when the selection is reloaded. Nothing is changed
+// if one of the selected providers is not available.
+func (e *Exporter) registerProviders() error {
+	if len(e.opt.Providers) == 0 {
+		return fmt.Errorf("no provider is selected, known providers: %v", sets.List(sets.KeySet(e.factories)))
+	}
+
+	for _, name := range e.opt.Providers {
+		factory, ok := e.factories[name]
+		if !ok {
+			return fmt.Errorf("unknown provider %q, known providers: %v", name, sets.List(sets.KeySet(e.factories)))
+		}
+		if factory.feature != "" && !e.featureGate.Enabled(factory.feature) {
+			return fmt.Errorf("provider %q requires the feature gate %s", name, factory.feature)
+		}
+	}
+
+	selected := sets.New(e.opt.Providers...)
+	e.providerMutex.Lock()
+	for name := range e.providers {
+		if !selected.Has(name) {
+			delete(e.providers, name)
+			klog.V(2).Infof("Removed provider %s", name)
+		}
+	}
+	registered := sets.KeySet(e.providers)
+	e.providerMutex.Unlock()
+
+	for _, name := range e.opt.Providers {
+		if registered.Has(name) {
+			continue
+		}
+		e.RegisterNumaType(e.factories[name].create(e))
+		klog.V(2).Infof("Registered provider %s", name)
+	}
+	return nil
+}
 
 // RegisterNumaType is the function to register the info provider
-func RegisterNumaType(info NumaInfo) {
-	numaMap[info.Name()] = info
+func (e *Exporter) RegisterNumaType(info NumaInfo) {
+	e.providerMutex.Lock()
+	defer e.providerMutex.Unlock()
+	e.providers[info.Name()] = info
 }
 
 // TopoInfoUpdate get the latest node topology information
-// if info is changed , return true
-func TopoInfoUpdate(opt *args.Argument) bool {
-	isChg := false
+// if info is changed , return true.
+// The providers are updated in parallel, each within --provider-update-timeout; a provider
+// which times out keeps its last snapshot and is not updated again until its update returns.
+func (e *Exporter) TopoInfoUpdate() bool {
+	var stale []StaleField
+	e.providerMutex.Lock()
+	providers := make(map[string]NumaInfo, len(e.providers))
+	for str, info := range e.providers {
+		if e.updating[str] {
+			klog.Warningf("Update of provider %s is still running, keeping its last snapshot", str)
+			stale = append(stale, StaleField{Provider: str, Reason: StaleReasonUpdateTimedOut, Message: "update is still running"})
+			continue
+		}
+		e.updating[str] = true
+		providers[str] = info
+	}
+	e.providerMutex.Unlock()
+
+	var wg sync.WaitGroup
+	results := make(chan providerResult, len(providers))
+	for str, info := range providers {
+		wg.Add(1)
+		go func(str string, info NumaInfo) {
+			defer wg.Done()
+			ret, err := e.updateProvider(str, info)
+			results <- providerResult{name: str, info: ret, err: err}
+		}(str, info)
+	}
+	wg.Wait()
+	close(results)
 
-	for str, info := range numaMap {
-		ret := info.Update(opt)
-		if ret == nil {
+	isChg := false
+	e.providerMutex.Lock()
+	for result := range results {
+		if result.err != nil {
+			klog.Errorf("%v", result.err)
+			stale = append(stale, StaleField{Provider: result.name, Reason: StaleReasonUpdateTimedOut, Message: result.err.Error()})
+			continue
+		}
+		if result.info == nil {
 			continue
 		}
 
-		numaMap[str] = ret
+		e.providers[result.name] = result.info
 		isChg = true
 	}
+	e.providerStale = stale
+	e.providerMutex.Unlock()
 
 	return isChg
 }
 
+// providerResult is the outcome of the update of one provider
+type providerResult struct {
+	name string
+	// info is the new snapshot of the provider, nil if it is unchanged
+	info NumaInfo
+	err  error
+}
+
+// updateProvider calls Update of info and waits for it at most --provider-update-timeout.
+// An update which times out is left running, the provider is marked updating until it returns.
+func (e *Exporter) updateProvider(name string, info NumaInfo) (NumaInfo, error) {
+	// the update may outlive the timeout, so it keeps the options it started with across reloads
+	opt := e.opt
+	done := make(chan NumaInfo, 1)
+	go func() {
+		defer func() {
+			e.providerMutex.Lock()
+			delete(e.updating, name)
+			e.providerMutex.Unlock()
+		}()
+		done <- info.Update(opt)
+	}()
+
+	timeout := opt.ProviderUpdateTimeout
+	if timeout <= 0 {
+		return <-done, nil
+	}
+	timer := e.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ret := <-done:
+		return ret, nil
+	case <-timer.C():
+		return nil, fmt.Errorf("update of provider %s timed out after %v, keeping its last snapshot", name, timeout)
+	}
+}
+
 // GetAllResAllocatableInfo returns the latest info about the allocatable nums of all resource
-func GetAllResAllocatableInfo() map[string]v1alpha1.ResourceInfo {
+func (e *Exporter) GetAllResAllocatableInfo() map[string]v1alpha1.ResourceInfo {
+	e.providerMutex.RLock()
+	defer e.providerMutex.RUnlock()
+
 	numaResMap := make(map[string]v1alpha1.ResourceInfo)
 
-	for str, info := range numaMap {
+	for str, info := range e.providers {
 		numaResMap[str] = info.GetResourceInfoMap()
 	}
 
 	return numaResMap
 }
 
-// GetCpusDetail returns the cpu capability topology info
-func GetCpusDetail() map[string]v1alpha1.CPUInfo {
-	for _, info := range numaMap {
-		obj := info.GetResTopoDetail()
-		cpuDetail, ok := obj.(map[string]v1alpha1.CPUInfo)
-		if !ok {
-			continue
-		}
+// GetTopologyDetail returns the topology detail contributed by all the providers
+func (e *Exporter) GetTopologyDetail() TopologyDetail {
+	e.providerMutex.RLock()
+	defer e.providerMutex.RUnlock()
 
-		return cpuDetail
+	var detail TopologyDetail
+	for _, name := range sets.List(sets.KeySet(e.providers)) {
+		e.providers[name].AddDetail(&detail)
 	}
 
-	return nil
+	return detail
+}
+
+// GetCpusDetail returns the cpu capability topology info
+func (e *Exporter) GetCpusDetail() map[string]v1alpha1.CPUInfo {
+	return e.GetTopologyDetail().CPUDetail
+}
+
+// GetCPUL3CacheDetail returns the L3 cache domain of every cpu which has one
+func (e *Exporter) GetCPUL3CacheDetail() map[string]int {
+	return e.GetTopologyDetail().CPUL3Cache
+}
+
+// GetPCIDevices returns the NUMA locality of the PCI devices, by address
+func (e *Exporter) GetPCIDevices() map[string]PCIDevice {
+	return e.GetTopologyDetail().PCIDevices
 }
 
 // GetPodAllocations returns the pod resource allocation info
-func GetPodAllocations() []v1alpha1.PodAllocation {
+func (e *Exporter) GetPodAllocations() []v1alpha1.PodAllocation {
+	e.providerMutex.RLock()
+	defer e.providerMutex.RUnlock()
+
 	var podAllocations []v1alpha1.PodAllocation
 
-	for _, info := range numaMap {
+	for _, info := range e.providers {
 		pas := info.GetPodAllocations()
 		if len(pas) > 0 {
 			podAllocations = append(podAllocations, pas...)
@@ -86,7 +236,3 @@ func GetPodAllocations() []v1alpha1.PodAllocation {
 
 	return podAllocations
 }
-
-func init() {
-	RegisterNumaType(NewCPUNumaInfo())
-}
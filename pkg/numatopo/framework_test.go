@@ -0,0 +1,205 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/featuregate"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+// fakeProvider is a NumaInfo whose Update returns the next generation, after blocking on block if it is set
+type fakeProvider struct {
+	name       string
+	generation int
+	block      chan struct{}
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Update(_ *args.Argument) NumaInfo {
+	if p.block != nil {
+		<-p.block
+	}
+	return &fakeProvider{name: p.name, generation: p.generation + 1, block: p.block}
+}
+
+func (p *fakeProvider) GetResourceInfoMap() v1alpha1.ResourceInfo {
+	return v1alpha1.ResourceInfo{Capacity: p.generation}
+}
+
+func (p *fakeProvider) AddDetail(_ *TopologyDetail) {}
+
+func (p *fakeProvider) GetPodAllocations() []v1alpha1.PodAllocation { return nil }
+
+func (p *fakeProvider) GetStaleFields() []StaleField { return nil }
+
+func TestTopoInfoUpdateTimeout(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+	e.opt.ProviderUpdateTimeout = 50 * time.Millisecond
+	e.providers = map[string]NumaInfo{}
+	block := make(chan struct{})
+	e.RegisterNumaType(&fakeProvider{name: "slow", block: block})
+	e.RegisterNumaType(&fakeProvider{name: "fast"})
+
+	if !e.TopoInfoUpdate() {
+		t.Fatalf("expected the fast provider to be updated")
+	}
+	resources := e.GetAllResAllocatableInfo()
+	if resources["fast"].Capacity != 1 {
+		t.Fatalf("expected the fast provider to be updated despite the slow one, got %+v", resources["fast"])
+	}
+	if resources["slow"].Capacity != 0 {
+		t.Fatalf("expected the last snapshot of the slow provider, got %+v", resources["slow"])
+	}
+	if len(e.providerStale) == 0 {
+		t.Fatalf("expected the timeout of the slow provider to be reported")
+	}
+
+	// the slow provider is not updated again while its update still runs
+	e.TopoInfoUpdate()
+	if resources := e.GetAllResAllocatableInfo(); resources["fast"].Capacity != 2 || resources["slow"].Capacity != 0 {
+		t.Fatalf("unexpected resources while the slow provider is still running: %+v", resources)
+	}
+	if len(e.providerStale) == 0 {
+		t.Fatalf("expected the still running slow provider to be reported")
+	}
+
+	close(block)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		e.providerMutex.RLock()
+		updating := e.updating["slow"]
+		e.providerMutex.RUnlock()
+		if !updating {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("update of the slow provider did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	e.TopoInfoUpdate()
+	if resources := e.GetAllResAllocatableInfo(); resources["slow"].Capacity != 1 {
+		t.Fatalf("expected the slow provider to be updated once it returned, got %+v", resources["slow"])
+	}
+	if len(e.providerStale) != 0 {
+		t.Fatalf("expected no stale provider, got %v", e.providerStale)
+	}
+}
+
+func TestTopoInfoUpdateConcurrentReaders(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+	e.providers = map[string]NumaInfo{}
+	e.RegisterNumaType(&fakeProvider{name: "a"})
+	e.RegisterNumaType(&fakeProvider{name: "b"})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					e.GetAllResAllocatableInfo()
+					e.GetPodAllocations()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		e.TopoInfoUpdate()
+	}
+	close(stop)
+	wg.Wait()
+
+	if resources := e.GetAllResAllocatableInfo(); resources["a"].Capacity != 100 || resources["b"].Capacity != 100 {
+		t.Fatalf("expected every provider updated 100 times, got %+v", resources)
+	}
+}
+
+func TestRegisterProviders(t *testing.T) {
+	const fakeFeature featuregate.Feature = "FakeProvider"
+	providerFactories["gated"] = providerFactory{
+		create:  func(_ *Exporter) NumaInfo { return &fakeProvider{name: "gated"} },
+		feature: fakeFeature,
+	}
+	t.Cleanup(func() { delete(providerFactories, "gated") })
+
+	newGate := func(enabled bool) featuregate.FeatureGate {
+		gate := featuregate.NewFeatureGate()
+		if err := gate.Add(map[featuregate.Feature]featuregate.FeatureSpec{fakeFeature: {Default: false, PreRelease: featuregate.Alpha}}); err != nil {
+			t.Fatalf("add feature: %v", err)
+		}
+		if err := gate.SetFromMap(map[string]bool{string(fakeFeature): enabled}); err != nil {
+			t.Fatalf("set feature: %v", err)
+		}
+		return gate
+	}
+	custom := WithProviderFactory("custom", func(_ *Exporter) NumaInfo { return &fakeProvider{name: "custom"} })
+
+	tests := []struct {
+		name      string
+		providers []string
+		options   []Option
+		expected  []string
+		expectErr bool
+	}{
+		{name: "default", providers: []string{args.DefaultProvider}, expected: []string{resourceCPU}},
+		{name: "none", providers: nil, expectErr: true},
+		{name: "unknown", providers: []string{"cpu", "gpu"}, expectErr: true},
+		{name: "gate disabled", providers: []string{"gated"}, options: []Option{WithFeatureGate(newGate(false))}, expectErr: true},
+		{name: "gate enabled", providers: []string{"cpu", "gated"}, options: []Option{WithFeatureGate(newGate(true))}, expected: []string{resourceCPU, "gated"}},
+		{name: "custom factory", providers: []string{"custom"}, options: []Option{custom}, expected: []string{"custom"}},
+		{name: "custom factory not selected", providers: []string{"cpu"}, options: []Option{custom}, expected: []string{resourceCPU}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := args.NewArgument()
+			opt.NodeName = "node-a"
+			opt.Providers = tt.providers
+			e, err := NewExporter(opt, append([]Option{WithHostRoot(t.TempDir())}, tt.options...)...)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error for providers %v", tt.providers)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewExporter failed: %v", err)
+			}
+			if got := sets.List(sets.KeySet(e.providers)); !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("expected providers %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned"
+	informers "volcano.sh/apis/pkg/client/informers/externalversions"
+	listers "volcano.sh/apis/pkg/client/listers/nodeinfo/v1alpha1"
+)
+
+// OrphanCollector deletes the Numatopology objects whose Node no longer exists.
+// The Node owner reference set by the exporter lets the Kubernetes garbage collector
+// do the same, the collector covers objects written without it, e.g. by older exporters.
+type OrphanCollector struct {
+	kubeClient kubernetes.Interface
+	client     versioned.Interface
+
+	nodeLister  corelisters.NodeLister
+	numaLister  listers.NumatopologyLister
+	cacheSynced []cache.InformerSynced
+	queue       workqueue.TypedRateLimitingInterface[string]
+}
+
+// NewOrphanCollector creates an OrphanCollector watching Nodes and Numatopologies through the given
+// informer factories, which are started by the caller.
+func NewOrphanCollector(kubeClient kubernetes.Interface, client versioned.Interface,
+	kubeFactory kubeinformers.SharedInformerFactory, numaFactory informers.SharedInformerFactory) (*OrphanCollector, error) {
+	nodeInformer := kubeFactory.Core().V1().Nodes()
+	numaInformer := numaFactory.Nodeinfo().V1alpha1().Numatopologies()
+
+	gc := newOrphanCollector(kubeClient, client, nodeInformer.Lister(), numaInformer.Lister())
+	gc.cacheSynced = []cache.InformerSynced{nodeInformer.Informer().HasSynced, numaInformer.Informer().HasSynced}
+
+	// every Numatopology is checked when it is seen and on every resync, and again when its Node is deleted
+	_, err := numaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: gc.enqueue,
+		UpdateFunc: func(_, newObj interface{}) {
+			gc.enqueue(newObj)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: gc.enqueue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return gc, nil
+}
+
+func newOrphanCollector(kubeClient kubernetes.Interface, client versioned.Interface,
+	nodeLister corelisters.NodeLister, numaLister listers.NumatopologyLister) *OrphanCollector {
+	return &OrphanCollector{
+		kubeClient: kubeClient,
+		client:     client,
+		nodeLister: nodeLister,
+		numaLister: numaLister,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "numatopology-gc"},
+		),
+	}
+}
+
+// Run waits for the informer caches and collects orphans with the given number of workers until ctx is done
+func (gc *OrphanCollector) Run(ctx context.Context, workers int) {
+	defer gc.queue.ShutDown()
+
+	klog.Infof("Starting Numatopology orphan collector")
+	if !cache.WaitForCacheSync(ctx.Done(), gc.cacheSynced...) {
+		klog.Errorf("Failed to sync informer caches of the orphan collector")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			for gc.processNextItem() {
+			}
+		}, time.Second)
+	}
+	<-ctx.Done()
+}
+
+// enqueue queues the name of a Numatopology or a Node, which is the same for the objects of one node
+func (gc *OrphanCollector) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("Failed to get the key of %v, err: %v", obj, err)
+		return
+	}
+	gc.queue.Add(key)
+}
+
+func (gc *OrphanCollector) processNextItem() bool {
+	name, quit := gc.queue.Get()
+	if quit {
+		return false
+	}
+	defer gc.queue.Done(name)
+
+	if err := gc.sync(name); err != nil {
+		klog.Errorf("Failed to collect Numatopology %s, will retry, err: %v", name, err)
+		gc.queue.AddRateLimited(name)
+		return true
+	}
+	gc.queue.Forget(name)
+	return true
+}
+
+// sync deletes the Numatopology name if the Node of the same name does not exist
+func (gc *OrphanCollector) sync(name string) error {
+	numaInfo, err := gc.numaLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = gc.nodeLister.Get(name)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	// the Node cache may lag behind a node which just registered, confirm with the API server
+	_, err = gc.kubeClient.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return gc.delete(numaInfo)
+}
+
+func (gc *OrphanCollector) delete(numaInfo *v1alpha1.Numatopology) error {
+	// the UID precondition keeps a Numatopology written by a new node of the same name
+	uid := numaInfo.UID
+	err := gc.client.NodeinfoV1alpha1().Numatopologies().Delete(context.TODO(), numaInfo.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Deleted Numatopology %s as its Node no longer exists", numaInfo.Name)
+	return nil
+}
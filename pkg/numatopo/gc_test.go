@@ -0,0 +1,78 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	listers "volcano.sh/apis/pkg/client/listers/nodeinfo/v1alpha1"
+)
+
+func TestOrphanCollectorSync(t *testing.T) {
+	numatopo := func(name string) *nodeinfov1alpha1.Numatopology {
+		return &nodeinfov1alpha1.Numatopology{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID("uid-" + name)}}
+	}
+	node := func(name string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	// node-a exists, node-b is gone, node-c just registered and is not in the Node cache yet
+	numaObjs := []*nodeinfov1alpha1.Numatopology{numatopo("node-a"), numatopo("node-b"), numatopo("node-c")}
+	client := fake.NewSimpleClientset(numaObjs[0], numaObjs[1], numaObjs[2])
+	kubeClient := kubefake.NewSimpleClientset(node("node-a"), node("node-c"))
+
+	numaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, obj := range numaObjs {
+		if err := numaIndexer.Add(obj); err != nil {
+			t.Fatalf("failed to add to cache: %v", err)
+		}
+	}
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := nodeIndexer.Add(node("node-a")); err != nil {
+		t.Fatalf("failed to add to cache: %v", err)
+	}
+
+	gc := newOrphanCollector(kubeClient, client, corelisters.NewNodeLister(nodeIndexer), listers.NewNumatopologyLister(numaIndexer))
+	defer gc.queue.ShutDown()
+
+	for _, name := range []string{"node-a", "node-b", "node-c", "node-d"} {
+		if err := gc.sync(name); err != nil {
+			t.Fatalf("sync %s failed: %v", name, err)
+		}
+	}
+
+	for name, kept := range map[string]bool{"node-a": true, "node-b": false, "node-c": true} {
+		_, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), name, metav1.GetOptions{})
+		if kept && err != nil {
+			t.Errorf("expected Numatopology %s to be kept, got %v", name, err)
+		}
+		if !kept && !apierrors.IsNotFound(err) {
+			t.Errorf("expected Numatopology %s to be deleted, got %v", name, err)
+		}
+	}
+}
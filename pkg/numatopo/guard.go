@@ -0,0 +1,174 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"fmt"
+	"strings"
+
+	machineinfov1 "github.com/google/cadvisor/info/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+
+	"volcano.sh/resource-exporter/pkg/machineinfo"
+)
+
+// regression is an implausible change of the topology, more likely a read glitch of sysfs or
+// the kubelet checkpoint than a real change
+type regression struct {
+	message string
+	// confirmed is true if a second source agrees with the change
+	confirmed bool
+}
+
+// regressionGuard holds back implausible regressions of the topology, so a transient read error
+// does not make the whole node look empty to the scheduler. A regression is published once it
+// persists for holdCycles periodic refreshes or the machine topology read at startup confirms it.
+// Refreshes triggered by file, sysfs and pod events come in bursts and are not counted.
+type regressionGuard struct {
+	exporter *Exporter
+	// holdCycles is the number of periodic refreshes a regression is held back for, 0 disables the guard
+	holdCycles int
+	// getMachineInfo returns the machine topology found by cadvisor at startup, nil if unknown
+	getMachineInfo func() *machineinfov1.MachineInfo
+
+	// holding is true while regressions are held back
+	holding bool
+	// cycles is the number of periodic refreshes since the current regressions were first held back
+	cycles int
+}
+
+func newRegressionGuard(exporter *Exporter) *regressionGuard {
+	return &regressionGuard{
+		exporter:       exporter,
+		holdCycles:     exporter.opt.RegressionHoldCycles,
+		getMachineInfo: machineinfo.GetMachineInfo,
+	}
+}
+
+// countCycle counts a periodic refresh towards the hold of the current regressions, it must be
+// called before the refresh
+func (g *regressionGuard) countCycle() {
+	if g.holding {
+		g.cycles++
+	}
+}
+
+// allow returns true if next may replace last, the spec published so far, which is nil if nothing
+// is published yet. Regressions are held back until they persist for holdCycles periodic refreshes.
+func (g *regressionGuard) allow(last, next *v1alpha1.NumatopoSpec) bool {
+	if g.holdCycles <= 0 || last == nil {
+		return true
+	}
+
+	regressions := findRegressions(last, next, g.getMachineInfo())
+	var unconfirmed []string
+	for _, r := range regressions {
+		if !r.confirmed {
+			unconfirmed = append(unconfirmed, r.message)
+		}
+	}
+	if len(unconfirmed) == 0 {
+		if len(regressions) > 0 {
+			klog.Infof("Publishing the topology change confirmed by the machine topology: %s", joinRegressions(regressions))
+		}
+		g.reset()
+		return true
+	}
+
+	message := strings.Join(unconfirmed, ", ")
+	if !g.holding {
+		g.holding, g.cycles = true, 0
+		klog.Warningf("Holding back the topology change as a likely read error for %d periodic refreshes: %s", g.holdCycles, message)
+		g.exporter.recordNodeEvent(v1.EventTypeWarning, ReasonRegressionHeld,
+			"Holding back the topology change as a likely read error, it is published if it persists for %d periodic refreshes: %s", g.holdCycles, message)
+		return false
+	}
+
+	if g.cycles >= g.holdCycles {
+		klog.Warningf("Publishing the topology change held back for %d periodic refreshes: %s", g.cycles, message)
+		g.exporter.recordNodeEvent(v1.EventTypeWarning, ReasonRegressionPublished,
+			"Published the topology change held back for %d periodic refreshes: %s", g.cycles, message)
+		g.holding, g.cycles = false, 0
+		return true
+	}
+
+	klog.Warningf("Holding back the topology change as a likely read error (%d/%d): %s", g.cycles, g.holdCycles, message)
+	return false
+}
+
+// reset forgets the held back regressions once the local state is plausible again
+func (g *regressionGuard) reset() {
+	if g.holding {
+		klog.Infof("Topology change held back for %d periodic refreshes disappeared", g.cycles)
+	}
+	g.holding, g.cycles = false, 0
+}
+
+// findRegressions returns the implausible changes from last to next: the total capacity
+// dropping to zero, NUMA nodes disappearing and every cpu becoming allocated at once.
+// Disappearing NUMA nodes are confirmed if mi, which may be nil, has as many as next.
+func findRegressions(last, next *v1alpha1.NumatopoSpec, mi *machineinfov1.MachineInfo) []regression {
+	var regressions []regression
+
+	if totalCapacity(last) > 0 && totalCapacity(next) == 0 {
+		regressions = append(regressions, regression{message: "total capacity dropped to zero"})
+	}
+
+	lastNodes, nextNodes := numaNodes(last), numaNodes(next)
+	if gone := lastNodes.Difference(nextNodes); gone.Len() > 0 {
+		regressions = append(regressions, regression{
+			message:   fmt.Sprintf("NUMA nodes %v disappeared", sets.List(gone)),
+			confirmed: mi != nil && len(mi.Topology) == nextNodes.Len(),
+		})
+	}
+
+	lastCPU, nextCPU := last.NumaResMap[resourceCPU], next.NumaResMap[resourceCPU]
+	if lastCPU.Allocatable != "" && nextCPU.Allocatable == "" && nextCPU.Capacity > 0 {
+		regressions = append(regressions, regression{message: "every cpu became allocated at once"})
+	}
+
+	return regressions
+}
+
+func joinRegressions(regressions []regression) string {
+	messages := make([]string, 0, len(regressions))
+	for _, r := range regressions {
+		messages = append(messages, r.message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+func totalCapacity(spec *v1alpha1.NumatopoSpec) int {
+	capacity := 0
+	for _, info := range spec.NumaResMap {
+		capacity += info.Capacity
+	}
+	return capacity
+}
+
+// numaNodes returns the NUMA nodes which have cpus in spec
+func numaNodes(spec *v1alpha1.NumatopoSpec) sets.Set[int] {
+	nodes := sets.New[int]()
+	for _, cpu := range spec.CPUDetail {
+		nodes.Insert(cpu.NUMANodeID)
+	}
+	return nodes
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"strings"
+	"testing"
+
+	machineinfov1 "github.com/google/cadvisor/info/v1"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// guardSpec returns a spec with one cpu on every NUMA node in nodes, of which allocatable are free
+func guardSpec(nodes []int, allocatable string) *nodeinfov1alpha1.NumatopoSpec {
+	spec := &nodeinfov1alpha1.NumatopoSpec{
+		NumaResMap: map[string]nodeinfov1alpha1.ResourceInfo{},
+		CPUDetail:  map[string]nodeinfov1alpha1.CPUInfo{},
+	}
+	for i, node := range nodes {
+		spec.CPUDetail[string(rune('0'+i))] = nodeinfov1alpha1.CPUInfo{NUMANodeID: node, SocketID: node, CoreID: i}
+	}
+	if len(nodes) > 0 {
+		spec.NumaResMap[resourceCPU] = nodeinfov1alpha1.ResourceInfo{Allocatable: allocatable, Capacity: len(nodes)}
+	}
+	return spec
+}
+
+func TestFindRegressions(t *testing.T) {
+	last := guardSpec([]int{0, 1}, "0-1")
+	twoNodes := &machineinfov1.MachineInfo{Topology: []machineinfov1.Node{{Id: 0}, {Id: 1}}}
+	oneNode := &machineinfov1.MachineInfo{Topology: []machineinfov1.Node{{Id: 0}}}
+
+	tests := []struct {
+		name      string
+		next      *nodeinfov1alpha1.NumatopoSpec
+		mi        *machineinfov1.MachineInfo
+		expected  []string
+		confirmed bool
+	}{
+		{
+			name: "unchanged",
+			next: guardSpec([]int{0, 1}, "0-1"),
+		},
+		{
+			name: "some cpus allocated",
+			next: guardSpec([]int{0, 1}, "1"),
+		},
+		{
+			name:     "empty topology",
+			next:     guardSpec(nil, ""),
+			mi:       twoNodes,
+			expected: []string{"total capacity dropped to zero", "NUMA nodes [0 1] disappeared"},
+		},
+		{
+			name:     "NUMA node disappeared",
+			next:     guardSpec([]int{0}, "0"),
+			mi:       twoNodes,
+			expected: []string{"NUMA nodes [1] disappeared"},
+		},
+		{
+			name:      "NUMA node disappeared in the machine topology too",
+			next:      guardSpec([]int{0}, "0"),
+			mi:        oneNode,
+			expected:  []string{"NUMA nodes [1] disappeared"},
+			confirmed: true,
+		},
+		{
+			name:     "every cpu allocated",
+			next:     guardSpec([]int{0, 1}, ""),
+			expected: []string{"every cpu became allocated at once"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regressions := findRegressions(last, test.next, test.mi)
+			if len(regressions) != len(test.expected) {
+				t.Fatalf("expected regressions %v, got %v", test.expected, regressions)
+			}
+			for i, r := range regressions {
+				if r.message != test.expected[i] {
+					t.Errorf("expected regression %q, got %q", test.expected[i], r.message)
+				}
+				if strings.HasPrefix(r.message, "NUMA nodes") && r.confirmed != test.confirmed {
+					t.Errorf("expected confirmed %v, got %v", test.confirmed, r.confirmed)
+				}
+			}
+		})
+	}
+}
+
+func TestRegressionGuardHoldsRegressions(t *testing.T) {
+	e, fake := newExporterWithFakeRecorder(t)
+	g := e.guard
+	g.holdCycles = 2
+	g.getMachineInfo = func() *machineinfov1.MachineInfo { return nil }
+
+	last, empty := guardSpec([]int{0, 1}, "0-1"), guardSpec(nil, "")
+	if !g.allow(nil, empty) {
+		t.Fatalf("expected the first spec to be published")
+	}
+	if !g.allow(last, guardSpec([]int{0, 1}, "1")) {
+		t.Fatalf("expected a plausible change to be published")
+	}
+
+	// a burst of triggered refreshes does not wear the hold down
+	for refresh := 1; refresh <= 10; refresh++ {
+		if g.allow(last, empty) {
+			t.Fatalf("expected the regression to be held back in refresh %d", refresh)
+		}
+	}
+	g.countCycle()
+	if g.allow(last, empty) {
+		t.Fatalf("expected the regression to be held back in the first periodic refresh")
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonRegressionHeld) {
+		t.Fatalf("expected a single %s Event, got %v", ReasonRegressionHeld, events)
+	}
+	g.countCycle()
+	if !g.allow(last, empty) {
+		t.Fatalf("expected the regression to be published once it persists")
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonRegressionPublished) {
+		t.Fatalf("expected a %s Event, got %v", ReasonRegressionPublished, events)
+	}
+
+	// a regression which disappears starts over
+	if g.allow(last, empty) {
+		t.Fatalf("expected a new regression to be held back")
+	}
+	g.countCycle()
+	if !g.allow(last, last) {
+		t.Fatalf("expected an unchanged spec to be published")
+	}
+	if g.allow(last, empty) {
+		t.Fatalf("expected the regression to be held back again after it disappeared")
+	}
+	if g.cycles != 0 {
+		t.Fatalf("expected the count to restart, got %d", g.cycles)
+	}
+}
+
+func TestRegressionGuardConfirmedByMachineInfo(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+	g := e.guard
+	g.holdCycles = 3
+	g.getMachineInfo = func() *machineinfov1.MachineInfo {
+		return &machineinfov1.MachineInfo{Topology: []machineinfov1.Node{{Id: 0}}}
+	}
+
+	if !g.allow(guardSpec([]int{0, 1}, "0-1"), guardSpec([]int{0}, "0")) {
+		t.Fatalf("expected the confirmed regression to be published right away")
+	}
+}
+
+func TestRegressionGuardDisabled(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+	if e.guard.holdCycles != 0 {
+		t.Fatalf("expected the guard to be disabled by default in tests")
+	}
+	if !e.guard.allow(guardSpec([]int{0, 1}, "0-1"), guardSpec(nil, "")) {
+		t.Fatalf("expected a disabled guard to publish every change")
+	}
+}
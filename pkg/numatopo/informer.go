@@ -86,3 +86,24 @@ func (c *NumatopoCache) Get() (*v1alpha1.Numatopology, error) {
 func (c *NumatopoCache) HasSynced() bool {
 	return c.informer.HasSynced()
 }
+
+// AddEventHandler calls handler whenever the Numatopology of the node is added, updated or deleted,
+// with nil for a deletion.
+func (c *NumatopoCache) AddEventHandler(handler func(obj *v1alpha1.Numatopology)) error {
+	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if numaInfo, ok := obj.(*v1alpha1.Numatopology); ok {
+				handler(numaInfo)
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			if numaInfo, ok := newObj.(*v1alpha1.Numatopology); ok {
+				handler(numaInfo)
+			}
+		},
+		DeleteFunc: func(_ interface{}) {
+			handler(nil)
+		},
+	})
+	return err
+}
@@ -27,6 +27,19 @@ type NumaInfo interface {
 	Name() string
 	Update(opt *args.Argument) NumaInfo
 	GetResourceInfoMap() v1alpha1.ResourceInfo
-	GetResTopoDetail() interface{}
+	// AddDetail adds the topology detail the provider collects to detail
+	AddDetail(detail *TopologyDetail)
 	GetPodAllocations() []v1alpha1.PodAllocation
+	// GetStaleFields returns the fields which could not be read in the last update and keep their previous value
+	GetStaleFields() []StaleField
+}
+
+// TopologyDetail is the detail of the node topology contributed by the providers
+type TopologyDetail struct {
+	// CPUDetail is the socket, core and NUMA node of every cpu, published in the Numatopology spec
+	CPUDetail map[string]v1alpha1.CPUInfo
+	// CPUL3Cache is the L3 cache domain of every cpu which has one
+	CPUL3Cache map[string]int
+	// PCIDevices is the NUMA locality of the PCI devices, by address
+	PCIDevices map[string]PCIDevice
 }
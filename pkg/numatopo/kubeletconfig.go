@@ -36,24 +36,72 @@ import (
 	"volcano.sh/resource-exporter/pkg/util"
 )
 
+const (
+	// ReservationSourceAnnotation is the Numatopology annotation recording how ResReserved was obtained
+	ReservationSourceAnnotation = "volcano.sh/reservation-source"
+
+	// ReservationSourceFlag means the reservation was given by --res-reserved,
+	// resources it does not list still come from the node status or kubelet configuration
+	ReservationSourceFlag = "res-reserved"
+	// ReservationSourceNodeStatus means the reservation is Node status.capacity - status.allocatable
+	ReservationSourceNodeStatus = "node-status"
+	// ReservationSourceKubeletConfig means the reservation is calculated from the kubelet configuration
+	ReservationSourceKubeletConfig = "kubelet-config"
+)
+
 type kubeletConfig struct {
-	topoPolicy  map[v1alpha1.PolicyName]string
-	resReserved map[string]string
+	topoPolicy        map[v1alpha1.PolicyName]string
+	topoScope         string
+	resReserved       map[string]string
+	reservationSource string
+	// reservedCPUs is the reservedSystemCPUs of kubelet, empty if the CPUs are reserved by quantity
+	reservedCPUs string
+	// kubeletReserved is what kubelet itself reserves, nil if it could not be determined
+	kubeletReserved v1.ResourceList
+	// collected is true once the kubelet configuration was read, the initial values are not reported as changes
+	collected bool
 }
 
-var config = &kubeletConfig{
-	topoPolicy:  make(map[v1alpha1.PolicyName]string),
-	resReserved: make(map[string]string),
+func newKubeletConfig() *kubeletConfig {
+	return &kubeletConfig{
+		topoPolicy: map[v1alpha1.PolicyName]string{
+			v1alpha1.CPUManagerPolicy:      "none",
+			v1alpha1.TopologyManagerPolicy: "none",
+		},
+		topoScope:   kubeletconfigv1beta1.ContainerTopologyManagerScope,
+		resReserved: make(map[string]string),
+	}
 }
 
 // GetPolicy return the topology manager policy on kubelet
-func GetPolicy() map[v1alpha1.PolicyName]string {
-	return config.topoPolicy
+func (e *Exporter) GetPolicy() map[v1alpha1.PolicyName]string {
+	return e.config.topoPolicy
+}
+
+// GetTopologyManagerScope return the topology manager scope on kubelet
+func (e *Exporter) GetTopologyManagerScope() string {
+	return e.config.topoScope
+}
+
+// GetReservedSystemCPUs return the cpus reserved by reservedSystemCPUs on kubelet
+func (e *Exporter) GetReservedSystemCPUs() string {
+	return e.config.reservedCPUs
 }
 
 // GetResReserved return the reserved info about all resource
-func GetResReserved() map[string]string {
-	return config.resReserved
+func (e *Exporter) GetResReserved() map[string]string {
+	return e.config.resReserved
+}
+
+// GetReservationSource return how the reserved info was obtained
+func (e *Exporter) GetReservationSource() string {
+	return e.config.reservationSource
+}
+
+// UseNodeStatusReservation makes reservations derived from the status of the Node in nodeCache,
+// falling back to the calculation from kubelet configuration when the Node is not readable.
+func (e *Exporter) UseNodeStatusReservation(nodeCache *NodeCache) {
+	e.getNode = nodeCache.Get
 }
 
 // GetKubeletConfigFromLocalFile get kubelet configuration from kubelet config file
@@ -71,60 +119,152 @@ func GetKubeletConfigFromLocalFile(kubeletConfigPath string) (*kubeletconfigv1be
 }
 
 // TryUpdatingResourceReservation try to update reservation based on opt.ResReserved and kubelet configuration
-func TryUpdatingResourceReservation(klConfig *kubeletconfigv1beta1.KubeletConfiguration, optResReserved map[string]string) bool {
+func (e *Exporter) TryUpdatingResourceReservation(klConfig *kubeletconfigv1beta1.KubeletConfiguration, optResReserved map[string]string) bool {
 	var isChange bool = false
 	policy := make(map[v1alpha1.PolicyName]string)
 	policy[v1alpha1.CPUManagerPolicy] = klConfig.CPUManagerPolicy
 	policy[v1alpha1.TopologyManagerPolicy] = klConfig.TopologyManagerPolicy
 
-	if !reflect.DeepEqual(config.topoPolicy, policy) {
-		for key := range config.topoPolicy {
-			config.topoPolicy[key] = policy[key]
+	if !reflect.DeepEqual(e.config.topoPolicy, policy) {
+		for _, key := range []v1alpha1.PolicyName{v1alpha1.CPUManagerPolicy, v1alpha1.TopologyManagerPolicy} {
+			if e.config.collected && e.config.topoPolicy[key] != policy[key] {
+				e.recordNodeEvent(v1.EventTypeNormal, ReasonPolicyChanged, "%s changed from %q to %q", key, e.config.topoPolicy[key], policy[key])
+			}
+		}
+		for key := range e.config.topoPolicy {
+			e.config.topoPolicy[key] = policy[key]
+		}
+		isChange = true
+	}
+
+	scope := klConfig.TopologyManagerScope
+	if scope == "" {
+		scope = kubeletconfigv1beta1.ContainerTopologyManagerScope
+	}
+	if e.config.topoScope != scope {
+		if e.config.collected {
+			e.recordNodeEvent(v1.EventTypeNormal, ReasonPolicyChanged, "TopologyManagerScope changed from %q to %q", e.config.topoScope, scope)
 		}
+		e.config.topoScope = scope
 		isChange = true
 	}
 
-	// TODO taking memory into consideration when memory topology is added into numa info
-	var cpuReserved string
-	if _, ok := optResReserved[string(v1.ResourceCPU)]; ok {
-		cpuReserved = optResReserved[string(v1.ResourceCPU)]
+	if e.config.reservedCPUs != klConfig.ReservedSystemCPUs {
+		klog.V(4).Infof("Reserved system cpus changed from %q to %q", e.config.reservedCPUs, klConfig.ReservedSystemCPUs)
+		e.config.reservedCPUs = klConfig.ReservedSystemCPUs
+		isChange = true
+	}
+
+	var reserved v1.ResourceList
+	source := ReservationSourceKubeletConfig
+	if nodeReserved, ok := e.nodeStatusReservation(); ok {
+		reserved = nodeReserved
+		source = ReservationSourceNodeStatus
 	} else {
 		// machine info is guaranteed at starting
 		mi := machineinfo.GetMachineInfo()
-		ReservedRes, err := calculateNodeResourceReservation(klConfig.KubeReserved, klConfig.SystemReserved, klConfig.EvictionHard, mi)
-		klog.Infof("%+v", ReservedRes)
+		calculated, err := calculateNodeResourceReservation(klConfig, mi)
 		// err won't happen regularly, unless there wrong configurations on kubelet, which would also lead to stop kubelet.
 		// so let just take the default value as 0
 		if err != nil {
-			cpuReserved = resource.NewQuantity(0, resource.DecimalSI).String()
-			klog.Warningf("failed to calculate cpu reservation, err: %v", err)
+			klog.Warningf("failed to calculate resource reservation, err: %v", err)
 		} else {
-			cpuReserved = ReservedRes.Cpu().String()
+			reserved = calculated
+		}
+	}
+	e.config.kubeletReserved = reserved
+
+	resReserved := make(map[string]string, len(reserved)+1)
+	// cpu is always published, 0 if nothing is reserved
+	resReserved[string(v1.ResourceCPU)] = resource.NewQuantity(0, resource.DecimalSI).String()
+	for name, quantity := range reserved {
+		if util.IsReservableResource(name) {
+			resReserved[string(name)] = quantity.String()
+		}
+	}
+	// resources given by --res-reserved take precedence
+	for name, quantity := range optResReserved {
+		resReserved[name] = quantity
+	}
+	if len(optResReserved) > 0 {
+		source = ReservationSourceFlag
+	}
+
+	if !reflect.DeepEqual(e.config.resReserved, resReserved) {
+		klog.V(4).Infof("Resource reservation changed from %v to %v", e.config.resReserved, resReserved)
+		if e.config.collected {
+			e.recordNodeEvent(v1.EventTypeNormal, ReasonReservationChanged, "Reserved resources changed from %v to %v", e.config.resReserved, resReserved)
 		}
+		e.config.resReserved = resReserved
+		isChange = true
 	}
 
-	if config.resReserved[string(v1.ResourceCPU)] != cpuReserved {
-		config.resReserved[string(v1.ResourceCPU)] = cpuReserved
+	if e.config.reservationSource != source {
+		klog.V(2).Infof("Resource reservation source changed from %q to %q", e.config.reservationSource, source)
+		e.config.reservationSource = source
 		isChange = true
 	}
 
+	e.config.collected = true
 	return isChange
 }
 
-func calculateNodeResourceReservation(kubeReserved, systemReserved, evictionHard map[string]string, mInfo *machineinfov1.MachineInfo) (v1.ResourceList, error) {
-	kubeRes, err := util.ParseResourceList(kubeReserved)
+// nodeStatusReservation returns the reservation kubelet enforces on this node,
+// false if node status reservation is disabled or the Node is not readable.
+func (e *Exporter) nodeStatusReservation() (v1.ResourceList, bool) {
+	if e.getNode == nil {
+		return nil, false
+	}
+
+	node, err := e.getNode()
+	if err != nil {
+		klog.Warningf("Failed to get Node, falling back to calculate reservation from kubelet configuration, err: %v", err)
+		return nil, false
+	}
+	if len(node.Status.Capacity) == 0 || len(node.Status.Allocatable) == 0 {
+		klog.Warningf("Node %s has not reported capacity and allocatable, falling back to calculate reservation from kubelet configuration", node.Name)
+		return nil, false
+	}
+
+	return calculateNodeStatusReservation(node.Status.Capacity, node.Status.Allocatable), true
+}
+
+// calculateNodeStatusReservation returns capacity - allocatable for every resource in capacity
+func calculateNodeStatusReservation(capacity, allocatable v1.ResourceList) v1.ResourceList {
+	result := make(v1.ResourceList)
+	for name, capQuantity := range capacity {
+		allocQuantity, ok := allocatable[name]
+		if !ok {
+			continue
+		}
+		value := capQuantity.DeepCopy()
+		value.Sub(allocQuantity)
+		if value.Sign() > 0 {
+			result[name] = value
+		}
+	}
+
+	return result
+}
+
+func calculateNodeResourceReservation(klConfig *kubeletconfigv1beta1.KubeletConfiguration, mInfo *machineinfov1.MachineInfo) (v1.ResourceList, error) {
+	if mInfo == nil {
+		return nil, fmt.Errorf("machine info is not initialized")
+	}
+
+	kubeRes, err := util.ParseResourceList(klConfig.KubeReserved)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse KubeReserved, err: %v", err)
 	}
 
-	systemRes, err := util.ParseResourceList(systemReserved)
+	systemRes, err := util.ParseResourceList(klConfig.SystemReserved)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse SystemReserved, err: %v", err)
 	}
 
-	hardEvictionThresholds, err := eviction.ParseThresholdConfig([]string{}, evictionHard, nil, nil, nil)
+	evictionThresholds, err := eviction.ParseThresholdConfig([]string{}, klConfig.EvictionHard, klConfig.EvictionSoft, klConfig.EvictionSoftGracePeriod, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse hard eviction, err: %v", err)
+		return nil, fmt.Errorf("failed to parse eviction thresholds, err: %v", err)
 	}
 
 	var (
@@ -133,10 +273,17 @@ func calculateNodeResourceReservation(kubeReserved, systemReserved, evictionHard
 	)
 
 	machineCapacity = cadvisor.CapacityFromMachineInfo(mInfo)
-	evictionReservation = util.HardEvictionReservation(hardEvictionThresholds, machineCapacity)
+	evictionReservation = util.HardEvictionReservation(evictionThresholds, machineCapacity)
+
+	metrics := make(map[v1.ResourceName]bool)
+	for _, rl := range []v1.ResourceList{machineCapacity, kubeRes, systemRes, evictionReservation} {
+		for metric := range rl {
+			metrics[metric] = true
+		}
+	}
 
 	result := make(v1.ResourceList)
-	for metric := range machineCapacity {
+	for metric := range metrics {
 		value := resource.NewQuantity(0, resource.DecimalSI)
 		if kubeRes != nil {
 			value.Add(kubeRes[metric])
@@ -154,8 +301,3 @@ func calculateNodeResourceReservation(kubeReserved, systemReserved, evictionHard
 
 	return result, nil
 }
-
-func init() {
-	config.topoPolicy[v1alpha1.CPUManagerPolicy] = "none"
-	config.topoPolicy[v1alpha1.TopologyManagerPolicy] = "none"
-}
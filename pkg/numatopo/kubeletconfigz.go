@@ -0,0 +1,210 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+const defaultConfigzTimeout = 5 * time.Second
+
+// configzGetter returns the raw body of the kubelet /configz endpoint
+type configzGetter func(ctx context.Context) ([]byte, error)
+
+// kubeletConfigz is the envelope kubelet wraps its versioned configuration in
+type kubeletConfigz struct {
+	KubeletConfig *kubeletconfigv1beta1.KubeletConfiguration `json:"kubeletconfig"`
+}
+
+// initKubeletConfigzClient prepares the client used to query kubelet /configz.
+// If --kubelet-configz-url is empty, the API server node proxy for the node is used,
+// otherwise the kubelet is queried directly with the credentials of the rest config. Its serving
+// certificate is verified with --kubelet-ca-file, falling back to the CA of the API server.
+func (e *Exporter) initKubeletConfigzClient() error {
+	configzURL, insecure := e.opt.KubeletConfigzURL, e.opt.KubeletInsecureTLS
+	if configzURL == "" {
+		if e.kubeClient == nil {
+			return fmt.Errorf("kube client is required to query kubelet configz through the API server")
+		}
+		kubeClient, nodeName := e.kubeClient, e.nodeName
+		e.getConfigz = func(ctx context.Context) ([]byte, error) {
+			return kubeClient.CoreV1().RESTClient().Get().
+				Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("configz").
+				DoRaw(ctx)
+		}
+		return nil
+	}
+	if e.restConfig == nil {
+		return fmt.Errorf("rest config is required to query kubelet configz at %s", configzURL)
+	}
+
+	restConfig := e.restConfig
+
+	kubeletConfig := rest.AnonymousClientConfig(restConfig)
+	kubeletConfig.BearerToken = restConfig.BearerToken
+	kubeletConfig.BearerTokenFile = restConfig.BearerTokenFile
+	kubeletConfig.TLSClientConfig = rest.TLSClientConfig{Insecure: insecure}
+	if !insecure && e.opt.KubeletCAFile != "" {
+		kubeletConfig.TLSClientConfig.CAFile = e.opt.KubeletCAFile
+	} else if !insecure {
+		// only works if the kubelet serving certificate is signed by the cluster CA
+		kubeletConfig.TLSClientConfig.CAFile = restConfig.TLSClientConfig.CAFile
+		kubeletConfig.TLSClientConfig.CAData = restConfig.TLSClientConfig.CAData
+	}
+	httpClient, err := rest.HTTPClientFor(kubeletConfig)
+	if err != nil {
+		return err
+	}
+	e.getConfigz = func(ctx context.Context) ([]byte, error) {
+		return fetchConfigz(ctx, httpClient, configzURL)
+	}
+	return nil
+}
+
+func fetchConfigz(ctx context.Context, httpClient *http.Client, configzURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configzURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kubelet configz returned %s: %s", resp.Status, string(body))
+	}
+
+	return body, nil
+}
+
+func decodeKubeletConfigz(data []byte) (*kubeletconfigv1beta1.KubeletConfiguration, error) {
+	configz := &kubeletConfigz{}
+	if err := json.Unmarshal(data, configz); err != nil {
+		return nil, fmt.Errorf("failed to decode kubelet configz, err: %v", err)
+	}
+	if configz.KubeletConfig == nil {
+		return nil, fmt.Errorf("kubelet configz has no kubeletconfig section")
+	}
+
+	return configz.KubeletConfig, nil
+}
+
+// GetKubeletConfigFromConfigz get the effective kubelet configuration from the kubelet /configz endpoint
+func (e *Exporter) GetKubeletConfigFromConfigz() (*kubeletconfigv1beta1.KubeletConfiguration, error) {
+	if e.getConfigz == nil {
+		return nil, fmt.Errorf("kubelet configz client is not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultConfigzTimeout)
+	defer cancel()
+
+	data, err := e.getConfigz(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubelet configz, err: %v", err)
+	}
+
+	return decodeKubeletConfigz(data)
+}
+
+// GetKubeletConfig get kubelet configuration from the source selected by --kubelet-config-source.
+// When /configz is used and a local config file is also given, the two are cross-checked.
+func (e *Exporter) GetKubeletConfig() (*kubeletconfigv1beta1.KubeletConfiguration, error) {
+	opt := e.opt
+	if opt.KubeletConfigSource != args.KubeletConfigSourceConfigz {
+		return GetKubeletConfigFromLocalFile(opt.KubeletConf)
+	}
+
+	klConfig, err := e.GetKubeletConfigFromConfigz()
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.KubeletConf != "" {
+		fileConfig, err := GetKubeletConfigFromLocalFile(opt.KubeletConf)
+		if err != nil {
+			klog.V(4).Infof("Skip cross-checking kubelet configz with %s, err: %v", opt.KubeletConf, err)
+		} else {
+			e.reportConfigzDiffs(opt.KubeletConf, diffKubeletConfig(fileConfig, klConfig))
+		}
+	}
+
+	return klConfig, nil
+}
+
+// reportConfigzDiffs reports diffs between the config file at path and /configz when they change,
+// a file which keeps disagreeing is not reported on every refresh
+func (e *Exporter) reportConfigzDiffs(path string, diffs []string) {
+	if reflect.DeepEqual(diffs, e.configzDiffs) {
+		return
+	}
+	e.configzDiffs = diffs
+
+	if len(diffs) == 0 {
+		klog.Infof("Kubelet config file %s agrees with the effective configuration again", path)
+		return
+	}
+	for _, diff := range diffs {
+		klog.Warningf("Kubelet config file %s disagrees with the effective configuration: %s", path, diff)
+	}
+	e.recordNodeEvent(v1.EventTypeWarning, ReasonKubeletConfigMismatch,
+		"Kubelet config file %s disagrees with the effective configuration: %s", path, strings.Join(diffs, ", "))
+}
+
+// diffKubeletConfig lists the fields relevant to the exporter that differ between the file and the effective configuration.
+// Fields left unset in the file are defaulted by kubelet, so they are not compared.
+func diffKubeletConfig(file, effective *kubeletconfigv1beta1.KubeletConfiguration) []string {
+	var diffs []string
+
+	if file.CPUManagerPolicy != "" && file.CPUManagerPolicy != effective.CPUManagerPolicy {
+		diffs = append(diffs, fmt.Sprintf("cpuManagerPolicy %q != %q", file.CPUManagerPolicy, effective.CPUManagerPolicy))
+	}
+	if file.TopologyManagerPolicy != "" && file.TopologyManagerPolicy != effective.TopologyManagerPolicy {
+		diffs = append(diffs, fmt.Sprintf("topologyManagerPolicy %q != %q", file.TopologyManagerPolicy, effective.TopologyManagerPolicy))
+	}
+	if file.KubeReserved != nil && !reflect.DeepEqual(file.KubeReserved, effective.KubeReserved) {
+		diffs = append(diffs, fmt.Sprintf("kubeReserved %v != %v", file.KubeReserved, effective.KubeReserved))
+	}
+	if file.SystemReserved != nil && !reflect.DeepEqual(file.SystemReserved, effective.SystemReserved) {
+		diffs = append(diffs, fmt.Sprintf("systemReserved %v != %v", file.SystemReserved, effective.SystemReserved))
+	}
+	if file.ReservedSystemCPUs != "" && file.ReservedSystemCPUs != effective.ReservedSystemCPUs {
+		diffs = append(diffs, fmt.Sprintf("reservedSystemCPUs %q != %q", file.ReservedSystemCPUs, effective.ReservedSystemCPUs))
+	}
+
+	return diffs
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+const configzBody = `{"kubeletconfig":{"cpuManagerPolicy":"static","topologyManagerPolicy":"single-numa-node","kubeReserved":{"cpu":"500m"}}}`
+
+// newConfigzServer starts a local HTTPS stand-in for the kubelet /configz endpoint
+func newConfigzServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/configz" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newConfigzExporter creates an Exporter querying the kubelet /configz endpoint at configzURL with token
+func newConfigzExporter(t *testing.T, token, configzURL string, insecure bool) *Exporter {
+	t.Helper()
+	e := newTestExporter(t, "node-a", WithRestConfig(&rest.Config{BearerToken: token}))
+	e.opt.KubeletConfigzURL, e.opt.KubeletInsecureTLS = configzURL, insecure
+	if err := e.initKubeletConfigzClient(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return e
+}
+
+func TestGetKubeletConfigFromConfigz(t *testing.T) {
+	t.Run("decodes the effective configuration over https", func(t *testing.T) {
+		srv := newConfigzServer(t, http.StatusOK, configzBody)
+		e := newConfigzExporter(t, "test-token", srv.URL+"/configz", true)
+
+		klConfig, err := e.GetKubeletConfigFromConfigz()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if klConfig.CPUManagerPolicy != "static" || klConfig.TopologyManagerPolicy != "single-numa-node" {
+			t.Fatalf("unexpected policies: %q %q", klConfig.CPUManagerPolicy, klConfig.TopologyManagerPolicy)
+		}
+		if klConfig.KubeReserved["cpu"] != "500m" {
+			t.Fatalf("expected kubeReserved cpu 500m, got %v", klConfig.KubeReserved)
+		}
+	})
+
+	t.Run("untrusted certificate fails without insecure", func(t *testing.T) {
+		srv := newConfigzServer(t, http.StatusOK, configzBody)
+		e := newConfigzExporter(t, "test-token", srv.URL+"/configz", false)
+		if _, err := e.GetKubeletConfigFromConfigz(); err == nil {
+			t.Fatalf("expected tls verification error")
+		}
+	})
+
+	t.Run("certificate verified with the kubelet CA file", func(t *testing.T) {
+		srv := newConfigzServer(t, http.StatusOK, configzBody)
+		caFile := filepath.Join(t.TempDir(), "kubelet-ca.crt")
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+
+		e := newTestExporter(t, "node-a", WithRestConfig(&rest.Config{BearerToken: "test-token"}))
+		e.opt.KubeletConfigzURL, e.opt.KubeletCAFile = srv.URL+"/configz", caFile
+		if err := e.initKubeletConfigzClient(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := e.GetKubeletConfigFromConfigz(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("non-200 status returns error", func(t *testing.T) {
+		srv := newConfigzServer(t, http.StatusOK, configzBody)
+		e := newConfigzExporter(t, "wrong", srv.URL+"/configz", true)
+		if _, err := e.GetKubeletConfigFromConfigz(); err == nil {
+			t.Fatalf("expected error for unauthorized response")
+		}
+	})
+
+	t.Run("missing kubeletconfig section returns error", func(t *testing.T) {
+		e := newTestExporter(t, "node-a")
+		e.getConfigz = func(ctx context.Context) ([]byte, error) {
+			return []byte(`{"other":{}}`), nil
+		}
+		if _, err := e.GetKubeletConfigFromConfigz(); err == nil {
+			t.Fatalf("expected error for missing kubeletconfig")
+		}
+	})
+
+	t.Run("uninitialized client returns error", func(t *testing.T) {
+		e := newTestExporter(t, "node-a")
+		if _, err := e.GetKubeletConfigFromConfigz(); err == nil {
+			t.Fatalf("expected error when client is not initialized")
+		}
+	})
+}
+
+func TestGetKubeletConfigSource(t *testing.T) {
+	e, fake := newExporterWithFakeRecorder(t)
+	e.getConfigz = func(ctx context.Context) ([]byte, error) {
+		return []byte(configzBody), nil
+	}
+
+	confPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(confPath, []byte("cpuManagerPolicy: none\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	e.opt.KubeletConfigSource, e.opt.KubeletConf = args.KubeletConfigSourceFile, confPath
+	klConfig, err := e.GetKubeletConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if klConfig.CPUManagerPolicy != "none" {
+		t.Fatalf("file source: expected none, got %q", klConfig.CPUManagerPolicy)
+	}
+
+	// configz wins over a disagreeing file, which is only used to cross-check
+	e.opt.KubeletConfigSource = args.KubeletConfigSourceConfigz
+	klConfig, err = e.GetKubeletConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if klConfig.CPUManagerPolicy != "static" {
+		t.Fatalf("configz source: expected static, got %q", klConfig.CPUManagerPolicy)
+	}
+
+	// the disagreement is reported once, not on every refresh
+	if _, err := e.GetKubeletConfig(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonKubeletConfigMismatch) {
+		t.Fatalf("expected a single %s Event, got %v", ReasonKubeletConfigMismatch, events)
+	}
+}
+
+func TestDiffKubeletConfig(t *testing.T) {
+	effective := &kubeletconfigv1beta1.KubeletConfiguration{
+		CPUManagerPolicy:      "static",
+		TopologyManagerPolicy: "none",
+		KubeReserved:          map[string]string{"cpu": "500m"},
+	}
+
+	if diffs := diffKubeletConfig(&kubeletconfigv1beta1.KubeletConfiguration{}, effective); len(diffs) != 0 {
+		t.Fatalf("unset fields must not be reported, got %v", diffs)
+	}
+
+	file := &kubeletconfigv1beta1.KubeletConfiguration{
+		CPUManagerPolicy: "none",
+		KubeReserved:     map[string]string{"cpu": "1"},
+	}
+	if diffs := diffKubeletConfig(file, effective); len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %v", diffs)
+	}
+}
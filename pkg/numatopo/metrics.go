@@ -0,0 +1,187 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+const (
+	metricsNamespace = "numatopo"
+	// metricsPath is the path the metrics are served on
+	metricsPath = "/metrics"
+
+	metricsShutdownTimeout = 5 * time.Second
+
+	// numatopoTarget is the publish target label of the Numatopology, the sinks are labeled by name
+	numatopoTarget = "Numatopology"
+
+	// the results of the PodResources calls
+	podResourcesResultSuccess = "success"
+	podResourcesResultError   = "error"
+)
+
+// exporterMetrics are the Prometheus metrics of one Exporter, kept in its own registry
+// so several Exporters can run in one process
+type exporterMetrics struct {
+	registry *prometheus.Registry
+
+	refreshDuration      prometheus.Histogram
+	providerUpdateErrors *prometheus.CounterVec
+	publishAttempts      *prometheus.CounterVec
+	publishConflicts     *prometheus.CounterVec
+	publishFailures      *prometheus.CounterVec
+	podResourcesDuration *prometheus.HistogramVec
+
+	// publishMutex guards lastPublish
+	publishMutex sync.Mutex
+	// lastPublish is when the topology was last published, the start of the Exporter before that
+	lastPublish time.Time
+}
+
+func newExporterMetrics(e *Exporter) *exporterMetrics {
+	m := &exporterMetrics{
+		registry: prometheus.NewRegistry(),
+		refreshDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "refresh_duration_seconds",
+			Help:      "Duration of collecting the local state of the node, the kubelet configuration, the topology and the allocations.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}),
+		providerUpdateErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "provider_update_errors_total",
+			Help:      "Number of refreshes in which a topology provider failed to read a part of the topology or timed out, by provider and stale reason.",
+		}, []string{"provider", "reason"}),
+		publishAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "publish_attempts_total",
+			Help:      "Number of writes of the topology, by target: the Numatopology or a sink.",
+		}, []string{"target"}),
+		publishConflicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "publish_conflicts_total",
+			Help:      "Number of writes of the topology which conflicted with a concurrent write and were retried, by target.",
+		}, []string{"target"}),
+		publishFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "publish_failures_total",
+			Help:      "Number of failed publishes of the topology, which are retried with backoff, by target.",
+		}, []string{"target"}),
+		podResourcesDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "podresources_list_duration_seconds",
+			Help:      "Latency of listing the CPU allocations with the kubelet PodResources API, by result.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"result"}),
+		lastPublish: e.clock.Now(),
+	}
+
+	allocationSource := func(source string) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Name:        "allocation_source",
+			Help:        "1 for the source the CPU allocations are currently read from, 0 for the others.",
+			ConstLabels: prometheus.Labels{"source": source},
+		}, func() float64 {
+			if e.GetAllocationSource() == source {
+				return 1
+			}
+			return 0
+		})
+	}
+	sinceLastPublish := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "seconds_since_last_publish",
+		Help:      "Seconds since the topology was last published successfully, or since the exporter started if it was not published yet.",
+	}, func() float64 {
+		m.publishMutex.Lock()
+		defer m.publishMutex.Unlock()
+		return e.clock.Since(m.lastPublish).Seconds()
+	})
+
+	m.registry.MustRegister(
+		m.refreshDuration,
+		m.providerUpdateErrors,
+		m.publishAttempts,
+		m.publishConflicts,
+		m.publishFailures,
+		m.podResourcesDuration,
+		allocationSource(AllocationSourcePodResources),
+		allocationSource(AllocationSourceCPUManagerState),
+		sinceLastPublish,
+	)
+	return m
+}
+
+// published records a successful publish at now
+func (m *exporterMetrics) published(now time.Time) {
+	m.publishMutex.Lock()
+	defer m.publishMutex.Unlock()
+	m.lastPublish = now
+}
+
+// MetricsGatherer returns the metrics of the Exporter, e.g. to serve them together with those of an embedding process
+func (e *Exporter) MetricsGatherer() prometheus.Gatherer {
+	return e.metrics.registry
+}
+
+// MetricsHandler returns the handler serving the metrics of the Exporter in the Prometheus format
+func (e *Exporter) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(e.metrics.registry, promhttp.HandlerOpts{})
+}
+
+// serveMetrics serves the metrics of the Exporter and of the process on address until ctx is done,
+// it returns once it listens
+func (e *Exporter) serveMetrics(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	// the metrics of the process are served alongside, they are not part of the Exporter's
+	process := prometheus.NewRegistry()
+	process.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(prometheus.Gatherers{e.metrics.registry, process}, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.Warningf("Failed to shut down the metrics server, err: %v", err)
+		}
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Errorf("Metrics server failed, err: %v", err)
+		}
+	}()
+	klog.V(2).Infof("Serving metrics on %s%s", listener.Addr(), metricsPath)
+	return nil
+}
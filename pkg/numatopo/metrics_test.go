@@ -0,0 +1,154 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+// metricValue returns the value of the counter or gauge name of e with labels,
+// or the number of observations if it is a histogram; 0 if there is none
+func metricValue(t *testing.T, e *Exporter, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := e.MetricsGatherer().Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched != len(labels) {
+				continue
+			}
+			switch {
+			case metric.Counter != nil:
+				return metric.GetCounter().GetValue()
+			case metric.Gauge != nil:
+				return metric.GetGauge().GetValue()
+			case metric.Histogram != nil:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestPublishMetrics(t *testing.T) {
+	const nodeName = "node-c"
+	clock := clocktesting.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := fake.NewSimpleClientset(&nodeinfov1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, ResourceVersion: "2"},
+	})
+	e := newTestExporter(t, nodeName, WithNumatopoClient(client), WithClock(clock))
+	target := map[string]string{"target": numatopoTarget}
+
+	clock.Step(time.Minute)
+	if got := metricValue(t, e, "numatopo_seconds_since_last_publish", nil); got != 60 {
+		t.Fatalf("expected a minute since the start before the first publish, got %v", got)
+	}
+
+	// the object is not cached yet, so the create conflicts and it is patched
+	if err := e.CreateOrUpdateNumatopo(nil); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+	if attempts, conflicts := metricValue(t, e, "numatopo_publish_attempts_total", target), metricValue(t, e, "numatopo_publish_conflicts_total", target); attempts != 2 || conflicts != 1 {
+		t.Fatalf("expected 2 attempts and 1 conflict, got %v and %v", attempts, conflicts)
+	}
+	if got := metricValue(t, e, "numatopo_seconds_since_last_publish", nil); got != 0 {
+		t.Fatalf("expected the publish to reset the time since the last one, got %v", got)
+	}
+
+	failFirst(client, "patch", 1, errors.New("etcd unavailable"))
+	clock.Step(time.Second)
+	stale := &nodeinfov1alpha1.Numatopology{ObjectMeta: metav1.ObjectMeta{Name: nodeName, ResourceVersion: "1"}}
+	if err := e.CreateOrUpdateNumatopo(stale); err == nil {
+		t.Fatalf("expected the patch to fail")
+	}
+	if got := metricValue(t, e, "numatopo_publish_failures_total", target); got != 1 {
+		t.Fatalf("expected 1 failure, got %v", got)
+	}
+	if got := metricValue(t, e, "numatopo_seconds_since_last_publish", nil); got != 1 {
+		t.Fatalf("expected a second since the last publish, got %v", got)
+	}
+}
+
+func TestRefreshMetrics(t *testing.T) {
+	root := t.TempDir()
+	writeHostTopology(t, root, []int{2, 2}, "0-3")
+	e := newTestExporter(t, "node-a", WithHostRoot(root))
+
+	e.NodeInfoRefresh()
+	if got := metricValue(t, e, "numatopo_refresh_duration_seconds", nil); got != 1 {
+		t.Fatalf("expected 1 observed refresh, got %v", got)
+	}
+	if got := metricValue(t, e, "numatopo_allocation_source", map[string]string{"source": AllocationSourceCPUManagerState}); got != 1 {
+		t.Fatalf("expected the allocations to be read from cpu_manager_state, got %v", got)
+	}
+	if got := metricValue(t, e, "numatopo_allocation_source", map[string]string{"source": AllocationSourcePodResources}); got != 0 {
+		t.Fatalf("expected the PodResources API not to be the source, got %v", got)
+	}
+
+	// two fields of the cpu provider fail to read, which is one failed update
+	if err := os.Remove(filepath.Join(root, "sys/devices/system/node/online")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "sys/devices/system/cpu/cpu3/topology/core_id")); err != nil {
+		t.Fatal(err)
+	}
+	e.NodeInfoRefresh()
+	errorLabels := map[string]string{"provider": resourceCPU, "reason": StaleReasonReadFailed}
+	if got := metricValue(t, e, "numatopo_provider_update_errors_total", errorLabels); got != 1 {
+		t.Fatalf("expected 1 provider update error, got %v", got)
+	}
+	e.NodeInfoRefresh()
+	if got := metricValue(t, e, "numatopo_provider_update_errors_total", errorLabels); got != 2 {
+		t.Fatalf("expected every refresh which fails to be counted, got %v", got)
+	}
+}
+
+func TestPodResourcesMetrics(t *testing.T) {
+	root := t.TempDir()
+	writeHostTopology(t, root, []int{2}, "0-1")
+	e := newTestExporter(t, "node-a", WithHostRoot(root), WithPodResourcesClient(&fakePodResourcesClient{err: errors.New("rpc broken")}))
+	e.opt.EnableGetCpuIDByPodResourceList = true
+
+	e.NodeInfoRefresh()
+	if got := metricValue(t, e, "numatopo_podresources_list_duration_seconds", map[string]string{"result": podResourcesResultError}); got != 1 {
+		t.Fatalf("expected 1 failed PodResources call, got %v", got)
+	}
+	if got := metricValue(t, e, "numatopo_allocation_source", map[string]string{"source": AllocationSourceCPUManagerState}); got != 1 {
+		t.Fatalf("expected the allocations to fall back to cpu_manager_state, got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+const (
+	// NFDFeatureFile is the name of the feature file written by NFDSink
+	NFDFeatureFile = "volcano-resource-exporter"
+	// NFDFeaturePrefix is the prefix of the features written by NFDSink,
+	// NFD labels them in its default feature.node.kubernetes.io namespace
+	NFDFeaturePrefix = "volcano-topology."
+)
+
+// NFDSink writes the topology into a Node Feature Discovery local feature file, so clusters
+// running NFD get it as Node labels without the exporter being allowed to write Nodes.
+type NFDSink struct {
+	featuresDir string
+}
+
+// NewNFDSink creates a NFDSink writing into the NFD features.d directory featuresDir
+func NewNFDSink(featuresDir string) *NFDSink {
+	return &NFDSink{
+		featuresDir: featuresDir,
+	}
+}
+
+// Name implements Sink
+func (s *NFDSink) Name() string {
+	return "NFD"
+}
+
+// Publish implements Sink, the feature file is only replaced if its content changed
+func (s *NFDSink) Publish(_ context.Context, snapshot *Snapshot) error {
+	content := nfdFeatures(snapshot)
+	path := filepath.Join(s.featuresDir, NFDFeatureFile)
+
+	current, err := ioutil.ReadFile(path)
+	if err == nil && bytes.Equal(current, content) {
+		klog.V(4).Infof("NFD feature file %s is up to date, skip writing", path)
+		return nil
+	}
+
+	// the content is written to a temporary file which is renamed over the feature file,
+	// so NFD never reads a partially written file
+	tmp, err := ioutil.TempFile(s.featuresDir, "."+NFDFeatureFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	klog.V(4).Infof("Wrote NFD feature file %s", path)
+	return nil
+}
+
+// nfdFeatures returns the feature file content for snapshot, one sorted name=value per line:
+// NUMA node count, kubelet policies, the cpus of every NUMA node and the number of PCI devices
+// of every class attached to it, e.g. volcano-topology.numa-node-0.pci-0200=2 for two ethernet
+// controllers.
+func nfdFeatures(snapshot *Snapshot) []byte {
+	summary := summarizeTopology(snapshot)
+	spec := snapshot.Numatopology.Spec
+
+	features := map[string]string{
+		"numa-nodes":              strconv.Itoa(summary.NUMANodes),
+		"cpu-manager-policy":      topologyPolicy(spec, v1alpha1.CPUManagerPolicy),
+		"topology-manager-policy": topologyPolicy(spec, v1alpha1.TopologyManagerPolicy),
+	}
+	if snapshot.TopologyManagerScope != "" {
+		features["topology-manager-scope"] = snapshot.TopologyManagerScope
+	}
+	for numaID, cpus := range summary.NUMACPUs {
+		features[fmt.Sprintf("numa-node-%d.cpus", numaID)] = strconv.Itoa(cpus)
+	}
+
+	pciDevices := make(map[string]int)
+	for _, device := range snapshot.PCIDevices {
+		pciDevices[fmt.Sprintf("numa-node-%d.pci-%s", device.NUMANode, device.Class)]++
+	}
+	for name, count := range pciDevices {
+		features[name] = strconv.Itoa(count)
+	}
+
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s%s=%s\n", NFDFeaturePrefix, name, features[name])
+	}
+	return buf.Bytes()
+}
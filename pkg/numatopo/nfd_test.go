@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+func TestNFDFeatures(t *testing.T) {
+	snapshot := sliceSnapshot(4)
+	snapshot.Numatopology.Spec.Policies = map[nodeinfov1alpha1.PolicyName]string{
+		nodeinfov1alpha1.TopologyManagerPolicy: "single-numa-node",
+	}
+	snapshot.TopologyManagerScope = "pod"
+	snapshot.PCIDevices = map[string]PCIDevice{
+		"0000:3b:00.0": {Class: "0200", NUMANode: 0},
+		"0000:3b:00.1": {Class: "0200", NUMANode: 0},
+		"0000:af:00.0": {Class: "0302", NUMANode: 1},
+	}
+
+	expected := `volcano-topology.cpu-manager-policy=none
+volcano-topology.numa-node-0.cpus=2
+volcano-topology.numa-node-0.pci-0200=2
+volcano-topology.numa-node-1.cpus=2
+volcano-topology.numa-node-1.pci-0302=1
+volcano-topology.numa-nodes=2
+volcano-topology.topology-manager-policy=single-numa-node
+volcano-topology.topology-manager-scope=pod
+`
+	if content := string(nfdFeatures(snapshot)); content != expected {
+		t.Fatalf("expected features:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestNFDSinkPublish(t *testing.T) {
+	featuresDir := t.TempDir()
+	sink := NewNFDSink(featuresDir)
+	snapshot := sliceSnapshot(4)
+	path := filepath.Join(featuresDir, NFDFeatureFile)
+
+	if err := sink.Publish(context.TODO(), snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read feature file failed: %v", err)
+	}
+	if string(content) != string(nfdFeatures(snapshot)) {
+		t.Fatalf("unexpected feature file:\n%s", content)
+	}
+	if files, _ := ioutil.ReadDir(featuresDir); len(files) != 1 {
+		t.Fatalf("expected only the feature file, got %d files", len(files))
+	}
+
+	// an unchanged topology is not written again
+	old := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	if err = sink.Publish(context.TODO(), snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if fi, err := os.Stat(path); err != nil || !fi.ModTime().Equal(old) {
+		t.Fatalf("expected the up to date feature file to be kept")
+	}
+
+	snapshot.Numatopology.Spec.Policies = map[nodeinfov1alpha1.PolicyName]string{
+		nodeinfov1alpha1.CPUManagerPolicy: "static",
+	}
+	if err = sink.Publish(context.TODO(), snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if content, _ = ioutil.ReadFile(path); string(content) != string(nfdFeatures(snapshot)) {
+		t.Fatalf("expected the changed feature file, got:\n%s", content)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// NodeCache manages the informer and lister for the v1.Node this exporter runs on.
+type NodeCache struct {
+	factory  informers.SharedInformerFactory
+	lister   corelisters.NodeLister
+	informer cache.SharedIndexInformer
+	nodeName string
+}
+
+// NewNodeCache creates a new NodeCache with filtered informer.
+// The informer only watches the Node object with the specified name.
+func NewNodeCache(client kubernetes.Interface, nodeName string) *NodeCache {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", nodeName).String()
+		}),
+	)
+
+	nodeInformer := factory.Core().V1().Nodes()
+
+	return &NodeCache{
+		factory:  factory,
+		lister:   nodeInformer.Lister(),
+		informer: nodeInformer.Informer(),
+		nodeName: nodeName,
+	}
+}
+
+// Start starts the informer goroutine.
+func (c *NodeCache) Start(stopCh <-chan struct{}) {
+	klog.V(2).Infof("Starting Node informer for node %s", c.nodeName)
+	c.factory.Start(stopCh)
+}
+
+// WaitForCacheSync waits for the informer cache to be synced.
+func (c *NodeCache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	klog.V(2).Infof("Waiting for Node informer cache to sync")
+	return cache.WaitForCacheSync(stopCh, c.informer.HasSynced)
+}
+
+// Get retrieves the Node for the current node from local cache.
+func (c *NodeCache) Get() (*v1.Node, error) {
+	return c.lister.Get(c.nodeName)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+const (
+	// NodeLabelPrefix is the prefix of the Node labels and annotations written by NodeLabelSink
+	NodeLabelPrefix = "topology.volcano.sh/"
+
+	// LabelNUMANodes is the number of NUMA nodes
+	LabelNUMANodes = NodeLabelPrefix + "numa-nodes"
+	// LabelSockets is the number of sockets
+	LabelSockets = NodeLabelPrefix + "sockets"
+	// LabelSMT is true if simultaneous multithreading is enabled
+	LabelSMT = NodeLabelPrefix + "smt"
+	// LabelCPUManagerPolicy is the kubelet CPU manager policy
+	LabelCPUManagerPolicy = NodeLabelPrefix + "cpu-manager-policy"
+	// LabelTopologyManagerPolicy is the kubelet topology manager policy
+	LabelTopologyManagerPolicy = NodeLabelPrefix + "topology-manager-policy"
+	// LabelTopologyHash is a stable hash of the hardware topology, equal on nodes with the same topology
+	LabelTopologyHash = NodeLabelPrefix + "hash"
+
+	// AnnotationNUMACPUs is the number of cpus of every NUMA node, e.g. 0=32,1=32
+	AnnotationNUMACPUs = NodeLabelPrefix + "numa-cpus"
+)
+
+// NodeLabelSink writes a summary of the topology onto the Node as labels and annotations,
+// so node affinity and queue rules can select nodes by topology.
+type NodeLabelSink struct {
+	client   kubernetes.Interface
+	nodeName string
+	// getNode returns the Node the labels are compared with, it is read from the API server
+	// unless UseNodeCache was called
+	getNode func(ctx context.Context) (*v1.Node, error)
+}
+
+// NewNodeLabelSink creates a NodeLabelSink writing to the Node nodeName
+func NewNodeLabelSink(client kubernetes.Interface, nodeName string) *NodeLabelSink {
+	return &NodeLabelSink{
+		client:   client,
+		nodeName: nodeName,
+		getNode: func(ctx context.Context) (*v1.Node, error) {
+			return client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		},
+	}
+}
+
+// UseNodeCache makes the sink compare the labels with the Node in nodeCache instead of getting it
+// on every publish, it must be called before the sink is added to the Publisher
+func (s *NodeLabelSink) UseNodeCache(nodeCache *NodeCache) {
+	s.getNode = func(context.Context) (*v1.Node, error) {
+		return nodeCache.Get()
+	}
+}
+
+// Name implements Sink
+func (s *NodeLabelSink) Name() string {
+	return "NodeLabels"
+}
+
+// Publish implements Sink, the Node is only patched if one of the labels or annotations changed
+func (s *NodeLabelSink) Publish(ctx context.Context, snapshot *Snapshot) error {
+	labels, annotations := nodeTopologyLabels(snapshot)
+
+	node, err := s.getNode(ctx)
+	if err != nil {
+		return err
+	}
+	if containsAll(node.Labels, labels) && containsAll(node.Annotations, annotations) {
+		klog.V(4).Infof("Topology labels of Node %s are up to date, skip patching", s.nodeName)
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      labels,
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.client.CoreV1().Nodes().Patch(ctx, s.nodeName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if err == nil {
+		klog.V(4).Infof("Patched topology labels of Node %s to %v", s.nodeName, labels)
+	}
+	return err
+}
+
+// nodeTopologyLabels returns the labels and annotations summarizing snapshot
+func nodeTopologyLabels(snapshot *Snapshot) (map[string]string, map[string]string) {
+	summary := summarizeTopology(snapshot)
+	spec := snapshot.Numatopology.Spec
+
+	labels := map[string]string{
+		LabelNUMANodes:             strconv.Itoa(summary.NUMANodes),
+		LabelSockets:               strconv.Itoa(summary.Sockets),
+		LabelSMT:                   strconv.FormatBool(summary.SMT),
+		LabelCPUManagerPolicy:      topologyPolicy(spec, v1alpha1.CPUManagerPolicy),
+		LabelTopologyManagerPolicy: topologyPolicy(spec, v1alpha1.TopologyManagerPolicy),
+		LabelTopologyHash:          summary.Hash,
+	}
+
+	numaIDs := make([]int, 0, len(summary.NUMACPUs))
+	for numaID := range summary.NUMACPUs {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+	numaCPUs := make([]string, 0, len(numaIDs))
+	for _, numaID := range numaIDs {
+		numaCPUs = append(numaCPUs, strconv.Itoa(numaID)+"="+strconv.Itoa(summary.NUMACPUs[numaID]))
+	}
+	annotations := map[string]string{
+		AnnotationNUMACPUs: strings.Join(numaCPUs, ","),
+	}
+
+	return labels, annotations
+}
+
+// containsAll returns true if every key of expected has the same value in actual
+func containsAll(actual, expected map[string]string) bool {
+	for key, value := range expected {
+		if current, ok := actual[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
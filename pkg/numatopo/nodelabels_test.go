@@ -0,0 +1,149 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+func TestNodeTopologyLabels(t *testing.T) {
+	snapshot := sliceSnapshot(4)
+	snapshot.Numatopology.Spec.Policies = map[nodeinfov1alpha1.PolicyName]string{
+		nodeinfov1alpha1.CPUManagerPolicy: "static",
+	}
+
+	labels, annotations := nodeTopologyLabels(snapshot)
+	expected := map[string]string{
+		LabelNUMANodes:             "2",
+		LabelSockets:               "2",
+		LabelSMT:                   "false",
+		LabelCPUManagerPolicy:      "static",
+		LabelTopologyManagerPolicy: "none",
+	}
+	for key, value := range expected {
+		if labels[key] != value {
+			t.Errorf("expected label %s=%s, got %q", key, value, labels[key])
+		}
+	}
+	if len(labels[LabelTopologyHash]) != topologyHashLength {
+		t.Errorf("expected a hash of %d digits, got %q", topologyHashLength, labels[LabelTopologyHash])
+	}
+	if annotations[AnnotationNUMACPUs] != "0=2,1=2" {
+		t.Errorf("expected numa cpus 0=2,1=2, got %q", annotations[AnnotationNUMACPUs])
+	}
+
+	// the hash only depends on the hardware, not on the allocation
+	hash := labels[LabelTopologyHash]
+	snapshot.Numatopology.Spec.NumaResMap = map[string]nodeinfov1alpha1.ResourceInfo{resourceCPU: {Allocatable: "0-1"}}
+	if labels, _ = nodeTopologyLabels(snapshot); labels[LabelTopologyHash] != hash {
+		t.Errorf("expected hash %s after an allocation change, got %s", hash, labels[LabelTopologyHash])
+	}
+
+	// two threads on core 0 of socket 0
+	snapshot.Numatopology.Spec.CPUDetail["2"] = nodeinfov1alpha1.CPUInfo{NUMANodeID: 0, SocketID: 0, CoreID: 0}
+	labels, _ = nodeTopologyLabels(snapshot)
+	if labels[LabelSMT] != "true" {
+		t.Errorf("expected smt true, got %s", labels[LabelSMT])
+	}
+	if labels[LabelTopologyHash] == hash {
+		t.Errorf("expected the hash to change with the topology")
+	}
+}
+
+func TestNodeLabelSinkPublish(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"kubernetes.io/hostname": "node-a"}},
+	})
+	sink := NewNodeLabelSink(kubeClient, "node-a")
+	snapshot := sliceSnapshot(4)
+
+	if err := sink.Publish(context.TODO(), snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "node-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get node failed: %v", err)
+	}
+	if node.Labels[LabelNUMANodes] != "2" || node.Labels["kubernetes.io/hostname"] != "node-a" {
+		t.Fatalf("unexpected labels %v", node.Labels)
+	}
+	if node.Annotations[AnnotationNUMACPUs] != "0=2,1=2" {
+		t.Fatalf("unexpected annotations %v", node.Annotations)
+	}
+
+	// an unchanged topology is not written again
+	kubeClient.ClearActions()
+	if err = sink.Publish(context.TODO(), snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Fatalf("expected no patch of an up to date Node")
+		}
+	}
+}
+
+func TestNodeLabelSinkUsesNodeCache(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	nodeCache := NewNodeCache(kubeClient, "node-a")
+	nodeCache.Start(ctx.Done())
+	if !nodeCache.WaitForCacheSync(ctx.Done()) {
+		t.Fatalf("node cache did not sync")
+	}
+	sink := NewNodeLabelSink(kubeClient, "node-a")
+	sink.UseNodeCache(nodeCache)
+	snapshot := sliceSnapshot(4)
+
+	kubeClient.ClearActions()
+	if err := sink.Publish(ctx, snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	err := wait.PollUntilContextCancel(ctx, 10*time.Millisecond, true, func(context.Context) (bool, error) {
+		node, err := nodeCache.Get()
+		return err == nil && node.Labels[LabelNUMANodes] == "2", nil
+	})
+	if err != nil {
+		t.Fatalf("expected the patched labels in the node cache: %v", err)
+	}
+	if err = sink.Publish(ctx, snapshot); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+
+	var patches int
+	for _, action := range kubeClient.Actions() {
+		switch action.GetVerb() {
+		case "get":
+			t.Fatalf("expected the Node to be read from the cache, got %v", action)
+		case "patch":
+			patches++
+		}
+	}
+	if patches != 1 {
+		t.Fatalf("expected 1 patch, got %d", patches)
+	}
+}
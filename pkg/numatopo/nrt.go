@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+
+	"volcano.sh/resource-exporter/pkg/util"
+)
+
+// NodeResourceTopologyResource is the resource of the NodeResourceTopology objects written by NRTSink
+var NodeResourceTopologyResource = schema.GroupVersionResource{
+	Group:    "topology.node.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "noderesourcetopologies",
+}
+
+const (
+	nrtKind     = "NodeResourceTopology"
+	nrtZoneType = "Node"
+
+	nrtAttributeTopologyManagerPolicy = "topologyManagerPolicy"
+	nrtAttributeTopologyManagerScope  = "topologyManagerScope"
+)
+
+// nrtFields are the top-level fields of a NodeResourceTopology owned by NRTSink
+var nrtFields = []string{"topologyPolicies", "attributes", "zones"}
+
+// NRTSink publishes the node topology as a NodeResourceTopology v1alpha2 object,
+// the format read by the NodeResourceTopologyMatch plugin of kubernetes-sigs/scheduler-plugins.
+type NRTSink struct {
+	client   dynamic.Interface
+	nodeName string
+}
+
+// NewNRTSink creates a NRTSink writing the NodeResourceTopology of nodeName
+func NewNRTSink(client dynamic.Interface, nodeName string) *NRTSink {
+	return &NRTSink{
+		client:   client,
+		nodeName: nodeName,
+	}
+}
+
+// Name implements Sink
+func (s *NRTSink) Name() string {
+	return nrtKind
+}
+
+// Publish implements Sink, the NodeResourceTopology is only updated if its zones, attributes or owner changed
+func (s *NRTSink) Publish(ctx context.Context, snapshot *Snapshot) error {
+	desired := nodeResourceTopology(s.nodeName, snapshot)
+	nrtClient := s.client.Resource(NodeResourceTopologyResource)
+
+	current, err := nrtClient.Get(ctx, s.nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = nrtClient.Create(ctx, desired, metav1.CreateOptions{FieldManager: FieldManager})
+		if err == nil {
+			klog.V(4).Infof("Created NodeResourceTopology for node %s successfully", s.nodeName)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	updated := current.DeepCopy()
+	for _, field := range nrtFields {
+		updated.Object[field] = desired.Object[field]
+	}
+	for _, owner := range desired.GetOwnerReferences() {
+		updated.SetOwnerReferences(replaceNodeOwner(updated.GetOwnerReferences(), owner))
+	}
+	if equality.Semantic.DeepEqual(current.Object, updated.Object) {
+		klog.V(4).Infof("NodeResourceTopology for node %s is up to date, skip updating", s.nodeName)
+		return nil
+	}
+
+	// the resourceVersion of current makes a concurrent write fail with a conflict, which is retried
+	_, err = nrtClient.Update(ctx, updated, metav1.UpdateOptions{FieldManager: FieldManager})
+	if err == nil {
+		klog.V(4).Infof("Updated NodeResourceTopology for node %s successfully", s.nodeName)
+	}
+	return err
+}
+
+// nodeResourceTopology translates snapshot into the NodeResourceTopology of nodeName.
+// Every NUMA node is a zone of type Node with its CPUs as the cpu resource; allocatable leaves out
+// the CPUs kubelet reserves and available additionally the CPUs exclusively allocated to containers.
+func nodeResourceTopology(nodeName string, snapshot *Snapshot) *unstructured.Unstructured {
+	spec := snapshot.Numatopology.Spec
+	policy := topologyPolicy(spec, v1alpha1.TopologyManagerPolicy)
+
+	nrt := &unstructured.Unstructured{Object: map[string]interface{}{
+		"topologyPolicies": []interface{}{nrtTopologyPolicy(policy, snapshot.TopologyManagerScope)},
+		"attributes": []interface{}{
+			map[string]interface{}{"name": nrtAttributeTopologyManagerPolicy, "value": policy},
+			map[string]interface{}{"name": nrtAttributeTopologyManagerScope, "value": snapshot.TopologyManagerScope},
+		},
+		"zones": nrtZones(spec, snapshot.ReservedSystemCPUs),
+	}}
+	nrt.SetAPIVersion(NodeResourceTopologyResource.GroupVersion().String())
+	nrt.SetKind(nrtKind)
+	nrt.SetName(nodeName)
+	nrt.SetOwnerReferences(snapshot.Numatopology.OwnerReferences)
+
+	return nrt
+}
+
+func nrtZones(spec v1alpha1.NumatopoSpec, reservedSystemCPUs string) []interface{} {
+	numaCPUs := make(map[int]int64)
+	cpuNUMA := make(map[int]int)
+	for id, info := range spec.CPUDetail {
+		cpuID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		numaCPUs[info.NUMANodeID]++
+		cpuNUMA[cpuID] = info.NUMANodeID
+	}
+
+	reserved := reservedCPUs(spec, reservedSystemCPUs)
+	numaReservedCPUs := make(map[int]int64)
+	for cpu := range reserved {
+		if numaID, ok := cpuNUMA[cpu]; ok {
+			numaReservedCPUs[numaID]++
+		}
+	}
+
+	numaFreeCPUs := make(map[int]int64)
+	if info, ok := spec.NumaResMap[resourceCPU]; ok {
+		freeCPUs, err := util.Parse(info.Allocatable)
+		if err != nil {
+			klog.Errorf("Parse allocatable cpus %q failed, err: %v", info.Allocatable, err)
+		}
+		for _, cpu := range freeCPUs {
+			// the reserved CPUs are in the shared pool of kubelet, but not available to exclusive allocations
+			if numaID, ok := cpuNUMA[cpu]; ok && !reserved.Has(cpu) {
+				numaFreeCPUs[numaID]++
+			}
+		}
+	}
+
+	numaIDs := make([]int, 0, len(numaCPUs))
+	for numaID := range numaCPUs {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+
+	zones := make([]interface{}, 0, len(numaIDs))
+	for _, numaID := range numaIDs {
+		zones = append(zones, map[string]interface{}{
+			"name": fmt.Sprintf("node-%d", numaID),
+			"type": nrtZoneType,
+			"resources": []interface{}{
+				map[string]interface{}{
+					"name":        resourceCPU,
+					"capacity":    resource.NewQuantity(numaCPUs[numaID], resource.DecimalSI).String(),
+					"allocatable": resource.NewQuantity(numaCPUs[numaID]-numaReservedCPUs[numaID], resource.DecimalSI).String(),
+					"available":   resource.NewQuantity(numaFreeCPUs[numaID], resource.DecimalSI).String(),
+				},
+			},
+		})
+	}
+
+	return zones
+}
+
+// reservedCPUs returns the CPUs kubelet reserves: reservedSystemCPUs if it is set, otherwise
+// as many CPUs as the reserved cpu quantity rounds up to, which the static policy takes by
+// topology, whole cores of the lowest socket first
+func reservedCPUs(spec v1alpha1.NumatopoSpec, reservedSystemCPUs string) sets.Set[int] {
+	if reservedSystemCPUs != "" {
+		cpus, err := util.Parse(reservedSystemCPUs)
+		if err != nil {
+			klog.Errorf("Parse reserved system cpus %q failed, err: %v", reservedSystemCPUs, err)
+		}
+		return sets.New(cpus...)
+	}
+
+	reserved := sets.New[int]()
+	quantity, err := resource.ParseQuantity(spec.ResReserved[resourceCPU])
+	if err != nil {
+		return reserved
+	}
+	count := int((quantity.MilliValue() + 999) / 1000)
+	if count <= 0 {
+		return reserved
+	}
+
+	type cpuKey struct{ socket, core, cpu int }
+	keys := make([]cpuKey, 0, len(spec.CPUDetail))
+	for id, info := range spec.CPUDetail {
+		cpuID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, cpuKey{socket: info.SocketID, core: info.CoreID, cpu: cpuID})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].socket != keys[j].socket {
+			return keys[i].socket < keys[j].socket
+		}
+		if keys[i].core != keys[j].core {
+			return keys[i].core < keys[j].core
+		}
+		return keys[i].cpu < keys[j].cpu
+	})
+	for i := 0; i < count && i < len(keys); i++ {
+		reserved.Insert(keys[i].cpu)
+	}
+	return reserved
+}
+
+// nrtTopologyPolicy returns the deprecated topologyPolicies value, still read by older consumers
+func nrtTopologyPolicy(policy, scope string) string {
+	level := "ContainerLevel"
+	if scope == kubeletconfigv1beta1.PodTopologyManagerScope {
+		level = "PodLevel"
+	}
+
+	switch policy {
+	case kubeletconfigv1beta1.SingleNumaNodeTopologyManagerPolicy:
+		return "SingleNUMANode" + level
+	case kubeletconfigv1beta1.RestrictedTopologyManagerPolicy:
+		return "Restricted" + level
+	case kubeletconfigv1beta1.BestEffortTopologyManagerPolicy:
+		return "BestEffort" + level
+	default:
+		return "None"
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+func nrtSnapshot(freeCPUs string) *Snapshot {
+	return &Snapshot{
+		Numatopology: &nodeinfov1alpha1.Numatopology{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: "node-a", UID: "uid-a"}},
+			},
+			Spec: nodeinfov1alpha1.NumatopoSpec{
+				Policies: map[nodeinfov1alpha1.PolicyName]string{
+					nodeinfov1alpha1.CPUManagerPolicy:      "static",
+					nodeinfov1alpha1.TopologyManagerPolicy: "single-numa-node",
+				},
+				NumaResMap: map[string]nodeinfov1alpha1.ResourceInfo{
+					"cpu": {Allocatable: freeCPUs, Capacity: 4},
+				},
+				CPUDetail: map[string]nodeinfov1alpha1.CPUInfo{
+					"0": {NUMANodeID: 0, SocketID: 0, CoreID: 0},
+					"1": {NUMANodeID: 0, SocketID: 0, CoreID: 1},
+					"2": {NUMANodeID: 1, SocketID: 1, CoreID: 0},
+					"3": {NUMANodeID: 1, SocketID: 1, CoreID: 1},
+				},
+			},
+		},
+		TopologyManagerScope: "pod",
+	}
+}
+
+func TestNodeResourceTopology(t *testing.T) {
+	nrt := nodeResourceTopology("node-a", nrtSnapshot("0-2"))
+
+	if nrt.GetAPIVersion() != "topology.node.k8s.io/v1alpha2" || nrt.GetKind() != "NodeResourceTopology" || nrt.GetName() != "node-a" {
+		t.Fatalf("unexpected type meta %s/%s %s", nrt.GetAPIVersion(), nrt.GetKind(), nrt.GetName())
+	}
+	if len(nrt.GetOwnerReferences()) != 1 || nrt.GetOwnerReferences()[0].UID != "uid-a" {
+		t.Fatalf("expected the Node owner, got %v", nrt.GetOwnerReferences())
+	}
+
+	policies, _, _ := unstructured.NestedStringSlice(nrt.Object, "topologyPolicies")
+	if !reflect.DeepEqual(policies, []string{"SingleNUMANodePodLevel"}) {
+		t.Errorf("unexpected topologyPolicies %v", policies)
+	}
+	expectedAttributes := []interface{}{
+		map[string]interface{}{"name": "topologyManagerPolicy", "value": "single-numa-node"},
+		map[string]interface{}{"name": "topologyManagerScope", "value": "pod"},
+	}
+	if !reflect.DeepEqual(nrt.Object["attributes"], expectedAttributes) {
+		t.Errorf("unexpected attributes %v", nrt.Object["attributes"])
+	}
+
+	expectedZones := []interface{}{
+		map[string]interface{}{
+			"name": "node-0",
+			"type": "Node",
+			"resources": []interface{}{
+				map[string]interface{}{"name": "cpu", "capacity": "2", "allocatable": "2", "available": "2"},
+			},
+		},
+		map[string]interface{}{
+			"name": "node-1",
+			"type": "Node",
+			"resources": []interface{}{
+				map[string]interface{}{"name": "cpu", "capacity": "2", "allocatable": "2", "available": "1"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(nrt.Object["zones"], expectedZones) {
+		t.Errorf("expected zones %v, got %v", expectedZones, nrt.Object["zones"])
+	}
+}
+
+func TestNRTReservedCPUs(t *testing.T) {
+	cpuResources := func(zones interface{}) [][2]string {
+		var got [][2]string
+		for _, zone := range zones.([]interface{}) {
+			resources := zone.(map[string]interface{})["resources"].([]interface{})
+			cpu := resources[0].(map[string]interface{})
+			got = append(got, [2]string{cpu["allocatable"].(string), cpu["available"].(string)})
+		}
+		return got
+	}
+
+	// reservedSystemCPUs names the CPUs, one on each NUMA node
+	snapshot := nrtSnapshot("0-3")
+	snapshot.ReservedSystemCPUs = "0,2"
+	if got, expected := cpuResources(nodeResourceTopology("node-a", snapshot).Object["zones"]), [][2]string{{"1", "1"}, {"1", "1"}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected allocatable and available %v with reservedSystemCPUs, got %v", expected, got)
+	}
+
+	// a reserved quantity is rounded up to whole CPUs, taken from the first cores
+	snapshot = nrtSnapshot("0-2")
+	snapshot.Numatopology.Spec.ResReserved = map[string]string{"cpu": "1500m"}
+	if got, expected := cpuResources(nodeResourceTopology("node-a", snapshot).Object["zones"]), [][2]string{{"0", "0"}, {"2", "1"}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected allocatable and available %v with a reserved quantity, got %v", expected, got)
+	}
+}
+
+func TestNRTTopologyPolicy(t *testing.T) {
+	testCases := []struct {
+		policy, scope, expected string
+	}{
+		{"single-numa-node", "container", "SingleNUMANodeContainerLevel"},
+		{"restricted", "pod", "RestrictedPodLevel"},
+		{"best-effort", "container", "BestEffortContainerLevel"},
+		{"none", "container", "None"},
+	}
+	for _, tc := range testCases {
+		if got := nrtTopologyPolicy(tc.policy, tc.scope); got != tc.expected {
+			t.Errorf("policy %s scope %s: expected %s, got %s", tc.policy, tc.scope, tc.expected, got)
+		}
+	}
+}
+
+func TestNRTSinkPublish(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{NodeResourceTopologyResource: "NodeResourceTopologyList"})
+	sink := NewNRTSink(client, "node-a")
+	get := func() *unstructured.Unstructured {
+		obj, err := client.Resource(NodeResourceTopologyResource).Get(context.TODO(), "node-a", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get NodeResourceTopology: %v", err)
+		}
+		return obj
+	}
+
+	if err := sink.Publish(context.TODO(), nrtSnapshot("0-3")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	created := get()
+
+	// unchanged: no write
+	client.ClearActions()
+	if err := sink.Publish(context.TODO(), nrtSnapshot("0-3")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "get" {
+			t.Fatalf("expected no write when up to date, got %s", action.GetVerb())
+		}
+	}
+
+	// fields of others are kept
+	created.SetLabels(map[string]string{"other": "keep"})
+	if _, err := client.Resource(NodeResourceTopologyResource).Update(context.TODO(), created, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to label NodeResourceTopology: %v", err)
+	}
+	if err := sink.Publish(context.TODO(), nrtSnapshot("1-3")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	updated := get()
+	if updated.GetLabels()["other"] != "keep" {
+		t.Errorf("expected the labels of others to be kept")
+	}
+	zones, _, _ := unstructured.NestedSlice(updated.Object, "zones")
+	available, _, _ := unstructured.NestedSlice(zones[0].(map[string]interface{}), "resources")
+	if got := available[0].(map[string]interface{})["available"]; got != "1" {
+		t.Errorf("expected 1 available cpu on node-0, got %v", got)
+	}
+}
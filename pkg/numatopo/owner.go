@@ -0,0 +1,66 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// initNodeOwner reads the Node the exporter runs on, so the Numatopology written for it
+// is owned by the Node and deleted by the garbage collector together with it.
+func (e *Exporter) initNodeOwner() error {
+	node, err := e.kubeClient.CoreV1().Nodes().Get(context.TODO(), e.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	// not blocking the deletion of the Node, which would require permissions on it the exporter does not need
+	e.nodeOwner = &metav1.OwnerReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "Node",
+		Name:       node.Name,
+		UID:        node.UID,
+	}
+	klog.V(2).Infof("Numatopology of node %s is owned by Node UID %s", e.nodeName, node.UID)
+	return nil
+}
+
+// setNodeOwner replaces the Node owner references of numaInfo by owner, other owners are kept
+func setNodeOwner(numaInfo *v1alpha1.Numatopology, owner *metav1.OwnerReference) {
+	if owner == nil {
+		return
+	}
+	numaInfo.OwnerReferences = replaceNodeOwner(numaInfo.OwnerReferences, *owner)
+}
+
+// replaceNodeOwner returns refs with the Node owner references replaced by owner
+func replaceNodeOwner(refs []metav1.OwnerReference, owner metav1.OwnerReference) []metav1.OwnerReference {
+	result := []metav1.OwnerReference{owner}
+	for _, ref := range refs {
+		if ref.APIVersion == owner.APIVersion && ref.Kind == owner.Kind {
+			continue
+		}
+		result = append(result, ref)
+	}
+	return result
+}
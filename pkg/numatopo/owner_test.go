@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+)
+
+func TestNumatopoOwnedByNode(t *testing.T) {
+	const nodeName = "node-f"
+	kubeClient := kubefake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName, UID: "new-uid"}})
+
+	// written before the Node was registered again, and owned by something else as well
+	otherOwner := metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Other", Name: "other", UID: "other-uid"}
+	client := fake.NewSimpleClientset(&nodeinfov1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "v1", Kind: "Node", Name: nodeName, UID: "old-uid"},
+				otherOwner,
+			},
+		},
+	})
+	e := newTestExporter(t, nodeName, WithKubeClient(kubeClient), WithNumatopoClient(client))
+	if err := e.initNodeOwner(); err != nil {
+		t.Fatalf("initNodeOwner failed: %v", err)
+	}
+
+	current, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get Numatopology: %v", err)
+	}
+	if err = e.CreateOrUpdateNumatopo(current); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+
+	updated, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get Numatopology: %v", err)
+	}
+	expected := []metav1.OwnerReference{
+		{APIVersion: "v1", Kind: "Node", Name: nodeName, UID: "new-uid"},
+		otherOwner,
+	}
+	if !reflect.DeepEqual(updated.OwnerReferences, expected) {
+		t.Fatalf("expected owners %v, got %v", expected, updated.OwnerReferences)
+	}
+	if !numatopoMatches(updated, e.desiredNumatopo()) {
+		t.Fatalf("expected the updated Numatopology to match the local state")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PCIDevice is the NUMA locality of a PCI device
+type PCIDevice struct {
+	// Class is the device class and subclass in hex, e.g. 0200 for ethernet controllers
+	Class string
+	// NUMANode is the NUMA node the device is attached to
+	NUMANode int
+}
+
+// getPCIDeviceLocality returns the PCI devices under pciPath attached to a NUMA node, by address.
+// Devices the kernel reports no NUMA node for, as on single node machines, are left out.
+// A device whose files cannot be read keeps its locality in previous, if any, and is reported in the returned error.
+func getPCIDeviceLocality(pciPath string, previous map[string]PCIDevice) (map[string]PCIDevice, error) {
+	devices := make(map[string]PCIDevice)
+	deviceDirs, err := filepath.Glob(filepath.Join(pciPath, "*"))
+	if err != nil {
+		return previous, fmt.Errorf("list PCI devices in %s failed, err: %v", pciPath, err)
+	}
+
+	var failed []string
+	var firstErr error
+	for _, deviceDir := range deviceDirs {
+		address := filepath.Base(deviceDir)
+		device, ok, err := readPCIDevice(deviceDir)
+		if err != nil {
+			failed = append(failed, address)
+			if firstErr == nil {
+				firstErr = err
+			}
+			if device, ok := previous[address]; ok {
+				devices[address] = device
+			}
+			continue
+		}
+		if ok {
+			devices[address] = device
+		}
+	}
+
+	if len(failed) > 0 {
+		return devices, fmt.Errorf("read PCI devices %s failed: %v", strings.Join(failed, ","), firstErr)
+	}
+	return devices, nil
+}
+
+// readPCIDevice returns the NUMA locality of the PCI device in deviceDir, false if it has none.
+// Missing files mean the kernel reports no locality, other read errors are returned.
+func readPCIDevice(deviceDir string) (PCIDevice, bool, error) {
+	data, err := ioutil.ReadFile(filepath.Join(deviceDir, "numa_node"))
+	if os.IsNotExist(err) {
+		return PCIDevice{}, false, nil
+	}
+	if err != nil {
+		return PCIDevice{}, false, err
+	}
+	numaNode, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || numaNode < 0 {
+		return PCIDevice{}, false, nil
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(deviceDir, "class"))
+	if os.IsNotExist(err) {
+		return PCIDevice{}, false, nil
+	}
+	if err != nil {
+		return PCIDevice{}, false, err
+	}
+	// the class file holds 0xCCSSPP, the programming interface is left out
+	class := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	if len(class) < 4 {
+		return PCIDevice{}, false, nil
+	}
+
+	return PCIDevice{Class: class[:4], NUMANode: numaNode}, true, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetPCIDeviceLocality(t *testing.T) {
+	pciPath := t.TempDir()
+	writeDevice := func(address, class, numaNode string) {
+		dir := filepath.Join(pciPath, address)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "class"), []byte(class+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "numa_node"), []byte(numaNode+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeDevice("0000:3b:00.0", "0x020000", "0")
+	writeDevice("0000:af:00.0", "0x030200", "1")
+	// no NUMA locality
+	writeDevice("0000:00:1f.0", "0x060100", "-1")
+
+	expected := map[string]PCIDevice{
+		"0000:3b:00.0": {Class: "0200", NUMANode: 0},
+		"0000:af:00.0": {Class: "0302", NUMANode: 1},
+	}
+	if devices, err := getPCIDeviceLocality(pciPath, nil); err != nil || !reflect.DeepEqual(devices, expected) {
+		t.Fatalf("expected %v, got %v, err: %v", expected, devices, err)
+	}
+	if devices, err := getPCIDeviceLocality(filepath.Join(pciPath, "missing"), nil); err != nil || len(devices) != 0 {
+		t.Fatalf("expected no devices, got %v, err: %v", devices, err)
+	}
+
+	// a device which cannot be read keeps its last known locality
+	if err := os.Remove(filepath.Join(pciPath, "0000:af:00.0", "numa_node")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(pciPath, "0000:af:00.0", "numa_node"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	devices, err := getPCIDeviceLocality(pciPath, expected)
+	if err == nil {
+		t.Fatalf("expected the unreadable device to be reported")
+	}
+	if !reflect.DeepEqual(devices, expected) {
+		t.Fatalf("expected the last known locality %v, got %v", expected, devices)
+	}
+}
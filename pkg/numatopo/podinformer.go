@@ -0,0 +1,204 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// podRefreshDebounce coalesces the pod changes of a burst, e.g. a job starting, into one refresh
+const podRefreshDebounce = 200 * time.Millisecond
+
+// PodCache manages the informer for the pods bound to the node this exporter runs on.
+// The PodResources API has no watch, so pod lifecycle changes are used to refresh the allocations.
+type PodCache struct {
+	factory  informers.SharedInformerFactory
+	informer cache.SharedIndexInformer
+	nodeName string
+	// clock times the debouncing of the allocation handlers
+	clock clock.WithDelayedExecution
+}
+
+// NewPodCache creates a new PodCache with filtered informer.
+// The informer only watches the pods bound to the node with the specified name.
+func NewPodCache(client kubernetes.Interface, nodeName string, clock clock.WithDelayedExecution) *PodCache {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+		}),
+	)
+
+	return &PodCache{
+		factory:  factory,
+		informer: factory.Core().V1().Pods().Informer(),
+		nodeName: nodeName,
+		clock:    clock,
+	}
+}
+
+// Start starts the informer goroutine.
+func (c *PodCache) Start(stopCh <-chan struct{}) {
+	klog.V(2).Infof("Starting Pod informer for node %s", c.nodeName)
+	c.factory.Start(stopCh)
+}
+
+// WaitForCacheSync waits for the informer cache to be synced.
+func (c *PodCache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	klog.V(2).Infof("Waiting for Pod informer cache to sync")
+	return cache.WaitForCacheSync(stopCh, c.informer.HasSynced)
+}
+
+// AddAllocationHandler calls onChange when the CPU allocations of the node may have changed:
+// a Guaranteed pod started running, as it may have got exclusive CPUs, or a pod terminated or
+// was deleted. The calls are debounced, the pods listed at start do not trigger a call.
+func (c *PodCache) AddAllocationHandler(onChange func()) error {
+	debounced := newDebouncer(c.clock, podRefreshDebounce, onChange)
+	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if pod, ok := obj.(*v1.Pod); ok && !isInInitialList && podAllocationChanged(nil, pod) {
+				debounced.trigger()
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			if newPod, ok := newObj.(*v1.Pod); ok && podAllocationChanged(oldPod, newPod) {
+				debounced.trigger()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			debounced.trigger()
+		},
+	})
+	return err
+}
+
+// AddTopologyAffinityErrorHandler calls onError with the pod when kubelet rejects a pod of the node
+// with TopologyAffinityError, which means the pod was scheduled from stale NUMA data of the node.
+func (c *PodCache) AddTopologyAffinityErrorHandler(onError func(pod *v1.Pod)) error {
+	_, err := c.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if pod, ok := obj.(*v1.Pod); ok && !isInInitialList && topologyAffinityRejected(nil, pod) {
+				onError(pod)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok := oldObj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			if newPod, ok := newObj.(*v1.Pod); ok && topologyAffinityRejected(oldPod, newPod) {
+				onError(newPod)
+			}
+		},
+	})
+	return err
+}
+
+// GetTopologyAffinityErrors returns the number of pods of the node kubelet rejected with TopologyAffinityError
+func (e *Exporter) GetTopologyAffinityErrors() uint64 {
+	return atomic.LoadUint64(&e.topologyAffinityErrors)
+}
+
+// recordTopologyAffinityError counts the rejection of pod with TopologyAffinityError and reports it as an Event on the Node
+func (e *Exporter) recordTopologyAffinityError(pod *v1.Pod) {
+	count := atomic.AddUint64(&e.topologyAffinityErrors, 1)
+	klog.Warningf("Pod %s/%s was rejected with %s, republishing the topology of node %s", pod.Namespace, pod.Name, pod.Status.Reason, e.nodeName)
+	e.recordNodeEvent(v1.EventTypeWarning, ReasonTopologyAffinityError,
+		"Pod %s/%s was rejected by kubelet as it was scheduled from stale NUMA data, republishing the topology (%d rejections so far)",
+		pod.Namespace, pod.Name, count)
+}
+
+// topologyAffinityRejected returns true if newPod was just rejected with TopologyAffinityError,
+// oldPod is nil if the pod was just added
+func topologyAffinityRejected(oldPod, newPod *v1.Pod) bool {
+	if newPod.Status.Reason != ReasonTopologyAffinityError {
+		return false
+	}
+	return oldPod == nil || oldPod.Status.Reason != ReasonTopologyAffinityError
+}
+
+// podAllocationChanged returns true if the transition of a pod from oldPod, nil if it was just
+// added, to newPod may have changed the CPU allocations of the node
+func podAllocationChanged(oldPod, newPod *v1.Pod) bool {
+	wasRunning, wasTerminated := false, false
+	if oldPod != nil {
+		wasRunning = oldPod.Status.Phase == v1.PodRunning
+		wasTerminated = podTerminated(oldPod)
+	}
+
+	if newPod.Status.Phase == v1.PodRunning && !wasRunning && newPod.Status.QOSClass == v1.PodQOSGuaranteed {
+		klog.V(4).Infof("Guaranteed pod %s/%s started running", newPod.Namespace, newPod.Name)
+		return true
+	}
+	if podTerminated(newPod) && !wasTerminated {
+		klog.V(4).Infof("Pod %s/%s terminated", newPod.Namespace, newPod.Name)
+		return true
+	}
+	return false
+}
+
+func podTerminated(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// debouncer calls fn once after the first trigger of every interval
+type debouncer struct {
+	mutex    sync.Mutex
+	pending  bool
+	clock    clock.WithDelayedExecution
+	interval time.Duration
+	fn       func()
+}
+
+func newDebouncer(clock clock.WithDelayedExecution, interval time.Duration, fn func()) *debouncer {
+	return &debouncer{
+		clock:    clock,
+		interval: interval,
+		fn:       fn,
+	}
+}
+
+func (d *debouncer) trigger() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.pending {
+		return
+	}
+	d.pending = true
+	d.clock.AfterFunc(d.interval, func() {
+		d.mutex.Lock()
+		d.pending = false
+		d.mutex.Unlock()
+		d.fn()
+	})
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestPodAllocationChanged(t *testing.T) {
+	pod := func(phase v1.PodPhase, qos v1.PodQOSClass) *v1.Pod {
+		return &v1.Pod{Status: v1.PodStatus{Phase: phase, QOSClass: qos}}
+	}
+
+	testCases := []struct {
+		name     string
+		oldPod   *v1.Pod
+		newPod   *v1.Pod
+		expected bool
+	}{
+		{"guaranteed pod starts running", pod(v1.PodPending, v1.PodQOSGuaranteed), pod(v1.PodRunning, v1.PodQOSGuaranteed), true},
+		{"burstable pod starts running", pod(v1.PodPending, v1.PodQOSBurstable), pod(v1.PodRunning, v1.PodQOSBurstable), false},
+		{"running guaranteed pod is updated", pod(v1.PodRunning, v1.PodQOSGuaranteed), pod(v1.PodRunning, v1.PodQOSGuaranteed), false},
+		{"running guaranteed pod is added", nil, pod(v1.PodRunning, v1.PodQOSGuaranteed), true},
+		{"pending pod is added", nil, pod(v1.PodPending, v1.PodQOSGuaranteed), false},
+		{"best effort pod succeeds", pod(v1.PodRunning, v1.PodQOSBestEffort), pod(v1.PodSucceeded, v1.PodQOSBestEffort), true},
+		{"pod fails", pod(v1.PodRunning, v1.PodQOSBurstable), pod(v1.PodFailed, v1.PodQOSBurstable), true},
+		{"failed pod is updated", pod(v1.PodFailed, v1.PodQOSBurstable), pod(v1.PodFailed, v1.PodQOSBurstable), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if changed := podAllocationChanged(tc.oldPod, tc.newPod); changed != tc.expected {
+				t.Fatalf("expected %v, got %v", tc.expected, changed)
+			}
+		})
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	var calls int32
+	fakeClock := clocktesting.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	d := newDebouncer(fakeClock, 50*time.Millisecond, func() { atomic.AddInt32(&calls, 1) })
+
+	for i := 0; i < 5; i++ {
+		d.trigger()
+	}
+	fakeClock.Step(49 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Fatalf("expected no call before the interval passed, got %d", got)
+	}
+	fakeClock.Step(time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected a burst to be coalesced into 1 call, got %d", got)
+	}
+
+	d.trigger()
+	fakeClock.Step(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected a later trigger to call again, got %d calls", got)
+	}
+}
+
+func TestTopologyAffinityRejected(t *testing.T) {
+	rejected := &v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, Reason: ReasonTopologyAffinityError}}
+	pending := &v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}}
+
+	if !topologyAffinityRejected(pending, rejected) || !topologyAffinityRejected(nil, rejected) {
+		t.Fatalf("expected the rejection to be detected")
+	}
+	if topologyAffinityRejected(rejected, rejected) {
+		t.Fatalf("expected a rejection to be reported once")
+	}
+	if topologyAffinityRejected(pending, &v1.Pod{Status: v1.PodStatus{Phase: v1.PodFailed, Reason: "OutOfcpu"}}) {
+		t.Fatalf("expected other rejections to be ignored")
+	}
+}
+
+func TestPodCacheTopologyAffinityError(t *testing.T) {
+	e, fake := newExporterWithFakeRecorder(t)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Namespace: "default"},
+		Spec:       v1.PodSpec{NodeName: "node-a"},
+		Status:     v1.PodStatus{Phase: v1.PodPending},
+	}
+	kubeClient := kubefake.NewSimpleClientset(pod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	podCache := NewPodCache(kubeClient, "node-a", clock.RealClock{})
+	errorsSeen := make(chan struct{}, 10)
+	if err := podCache.AddTopologyAffinityErrorHandler(func(pod *v1.Pod) {
+		e.recordTopologyAffinityError(pod)
+		errorsSeen <- struct{}{}
+	}); err != nil {
+		t.Fatalf("add handler failed: %v", err)
+	}
+	podCache.Start(ctx.Done())
+	if !podCache.WaitForCacheSync(ctx.Done()) {
+		t.Fatalf("pod cache did not sync")
+	}
+
+	before := e.GetTopologyAffinityErrors()
+	rejected := pod.DeepCopy()
+	rejected.Status = v1.PodStatus{Phase: v1.PodFailed, Reason: ReasonTopologyAffinityError}
+	if _, err := kubeClient.CoreV1().Pods("default").UpdateStatus(ctx, rejected, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod failed: %v", err)
+	}
+
+	select {
+	case <-errorsSeen:
+	case <-ctx.Done():
+		t.Fatalf("expected the rejection to be handled")
+	}
+	if got := e.GetTopologyAffinityErrors(); got != before+1 {
+		t.Fatalf("expected %d rejections, got %d", before+1, got)
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonTopologyAffinityError+" Pod default/pod-a") {
+		t.Fatalf("unexpected Events %v", events)
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
-	podresv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
 	"k8s.io/kubernetes/pkg/kubelet/apis/podresources"
 )
 
@@ -30,20 +29,14 @@ const (
 	defaultMaxSize           = 1024 * 1024 * 16
 )
 
-var (
-	client podresv1.PodResourcesListerClient
-	conn   *grpc.ClientConn
-)
-
-func InitPodResourcesClient(sockDir string) error {
-	sockPath := filepath.Join(sockDir, "kubelet.sock")
-	var err error
-	client, conn, err = podresources.GetV1Client("unix://"+sockPath, defaultConnectionTimeout, defaultMaxSize)
-	return err
-}
-
-func ClosePodResourcesClient() {
-	if conn != nil {
-		conn.Close()
+// connectPodResources connects to the kubelet pod-resources socket, the returned connection
+// is to be closed once the exporter stops
+func (e *Exporter) connectPodResources() (*grpc.ClientConn, error) {
+	sockPath := filepath.Join(e.opt.PodResourceSockPath, "kubelet.sock")
+	client, conn, err := podresources.GetV1Client("unix://"+sockPath, defaultConnectionTimeout, defaultMaxSize)
+	if err != nil {
+		return nil, err
 	}
+	e.podResources = client
+	return conn, nil
 }
@@ -0,0 +1,253 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+const (
+	// publishKey is the only item of the queue, as the exporter publishes a single Numatopology
+	publishKey = "numatopology"
+
+	publishRetryBaseDelay = 500 * time.Millisecond
+	publishRetryMaxDelay  = 5 * time.Minute
+
+	// publishFailureEventThreshold is the number of failures in a row reported as an Event
+	publishFailureEventThreshold = 5
+)
+
+// PublishStatus reports whether the desired state has been written to the Numatopology and every Sink
+type PublishStatus struct {
+	// Pending is true while the latest desired state has not been written
+	Pending bool
+	// Failures is the number of consecutive failed writes
+	Failures int
+	// LastError is the error of the last failed write, nil after a successful one
+	LastError error
+	// LastPublished is the time the object was last written or found to match the desired state
+	LastPublished time.Time
+}
+
+// Publisher writes the desired Numatopology of the node, and the same state to every Sink,
+// through a rate-limited work queue. The desired state is kept until it is written, so a
+// failed write is retried with exponential backoff even if the local state does not change
+// again. Every write is compared with the object in the informer cache first and skipped
+// if they match.
+type Publisher struct {
+	exporter *Exporter
+	cache    *NumatopoCache
+	nodeName string
+	queue    workqueue.TypedRateLimitingInterface[string]
+	sinks    []Sink
+
+	mutex   sync.Mutex
+	desired *Snapshot
+	// version is increased on every Enqueue, so a write of an older desired state does not clear Pending
+	version uint64
+	status  PublishStatus
+}
+
+// NewPublisher creates a Publisher for the Numatopology of the node of exporter, cache is used to read the current object
+func NewPublisher(exporter *Exporter, cache *NumatopoCache) *Publisher {
+	return &Publisher{
+		exporter: exporter,
+		cache:    cache,
+		nodeName: exporter.nodeName,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[string](publishRetryBaseDelay, publishRetryMaxDelay),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "numatopology"},
+		),
+	}
+}
+
+// AddSink makes the Publisher write every desired state to sink as well, it must be called before Run
+func (p *Publisher) AddSink(sink Sink) {
+	klog.V(2).Infof("Publishing node topology to sink %s", sink.Name())
+	p.sinks = append(p.sinks, sink)
+}
+
+// Enqueue takes the local state as the desired state and schedules writing it if it changed.
+// An unchanged state is not queued again, so a failing write keeps backing off; drift of the
+// object itself is caught by OnNumatopoChange. An implausible regression of the topology is
+// held back for --regression-hold-cycles, see regressionGuard; only the spec is held back, the
+// annotations such as the conditions are still published with the last spec.
+func (p *Publisher) Enqueue() {
+	desired := p.exporter.Snapshot()
+
+	p.mutex.Lock()
+	if last := p.lastSpec(); !p.exporter.guard.allow(last, &desired.Numatopology.Spec) {
+		desired.Numatopology.Spec = *last.DeepCopy()
+	}
+	if desired.equal(p.desired) {
+		p.mutex.Unlock()
+		return
+	}
+	p.desired = desired
+	p.version++
+	p.status.Pending = true
+	p.mutex.Unlock()
+
+	p.queue.Add(publishKey)
+}
+
+// lastSpec returns the spec desired so far, or the one of the Numatopology published before the
+// exporter started, nil if there is none. It must be called with the mutex held.
+func (p *Publisher) lastSpec() *v1alpha1.NumatopoSpec {
+	if p.desired != nil {
+		return &p.desired.Numatopology.Spec
+	}
+	cached, err := p.cache.Get()
+	if err != nil {
+		return nil
+	}
+	return &cached.Spec
+}
+
+// OnNumatopoChange schedules writing the desired state again if obj, the Numatopology seen by the
+// informer, does not match it, so edits and deletions by others are reverted.
+// It is meant to be registered with NumatopoCache.AddEventHandler.
+func (p *Publisher) OnNumatopoChange(obj *v1alpha1.Numatopology) {
+	p.mutex.Lock()
+	desired := p.desired
+	if desired == nil || (obj != nil && numatopoMatches(obj, desired.Numatopology)) {
+		p.mutex.Unlock()
+		return
+	}
+	p.status.Pending = true
+	p.mutex.Unlock()
+
+	klog.V(3).Infof("Numatopology of node %s differs from the local state, reconciling", p.nodeName)
+	p.queue.Add(publishKey)
+}
+
+// Resync queues the desired state to be published again, even if it is unchanged, so the
+// Numatopology and every Sink are checked against it right away
+func (p *Publisher) Resync() {
+	p.mutex.Lock()
+	if p.desired == nil {
+		p.mutex.Unlock()
+		return
+	}
+	p.status.Pending = true
+	p.mutex.Unlock()
+
+	p.queue.Add(publishKey)
+}
+
+// Run writes the queued desired states until ctx is done
+func (p *Publisher) Run(ctx context.Context) {
+	klog.V(2).Infof("Starting Numatopology publisher for node %s", p.nodeName)
+	go func() {
+		<-ctx.Done()
+		p.queue.ShutDown()
+	}()
+
+	for p.processNextItem() {
+	}
+}
+
+// Status returns whether the last Enqueue-d state has been published
+func (p *Publisher) Status() PublishStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.status
+}
+
+func (p *Publisher) processNextItem() bool {
+	key, quit := p.queue.Get()
+	if quit {
+		return false
+	}
+	defer p.queue.Done(key)
+
+	p.mutex.Lock()
+	desired, version := p.desired, p.version
+	p.mutex.Unlock()
+
+	err := p.publish(desired)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err != nil {
+		p.status.Failures++
+		p.status.LastError = err
+		klog.Errorf("Publish Numatopo for node %s failed %d time(s), will retry, err=%v", p.nodeName, p.status.Failures, err)
+		if p.status.Failures == publishFailureEventThreshold {
+			p.exporter.recordNodeEvent(v1.EventTypeWarning, ReasonPublishFailed, "Publishing the topology failed %d times in a row, will retry, err: %v", p.status.Failures, err)
+		}
+		p.queue.AddRateLimited(key)
+		return true
+	}
+
+	p.queue.Forget(key)
+	if p.status.Failures >= publishFailureEventThreshold {
+		p.exporter.recordNodeEvent(v1.EventTypeNormal, ReasonPublishRecovered, "Published the topology after %d failed attempts", p.status.Failures)
+	}
+	p.status.Failures = 0
+	p.status.LastError = nil
+	p.status.LastPublished = p.exporter.clock.Now()
+	p.exporter.metrics.published(p.status.LastPublished)
+	if version == p.version {
+		p.status.Pending = false
+	}
+	return true
+}
+
+func (p *Publisher) publish(desired *Snapshot) error {
+	if desired == nil {
+		return nil
+	}
+
+	// sinks are written even if the Numatopology is not, they do not depend on each other
+	var errs []error
+	if err := p.writeNumatopo(desired.Numatopology); err != nil {
+		errs = append(errs, err)
+	}
+	for _, sink := range p.sinks {
+		p.exporter.metrics.publishAttempts.WithLabelValues(sink.Name()).Inc()
+		if err := sink.Publish(context.TODO(), desired); err != nil {
+			p.exporter.metrics.publishFailures.WithLabelValues(sink.Name()).Inc()
+			errs = append(errs, fmt.Errorf("sink %s: %w", sink.Name(), err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (p *Publisher) writeNumatopo(desired *v1alpha1.Numatopology) error {
+	cached, err := p.cache.Get()
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		cached = nil
+	}
+
+	return p.exporter.publishNumatopo(cached, desired)
+}
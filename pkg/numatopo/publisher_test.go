@@ -0,0 +1,265 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	machineinfov1 "github.com/google/cadvisor/info/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+	"volcano.sh/apis/pkg/client/clientset/versioned/fake"
+	listers "volcano.sh/apis/pkg/client/listers/nodeinfo/v1alpha1"
+)
+
+// failFirst makes the first n calls of verb on Numatopologies fail with err
+func failFirst(client *fake.Clientset, verb string, n int, err error) *int {
+	calls := 0
+	client.PrependReactor(verb, "numatopologies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		if calls <= n {
+			return true, nil, err
+		}
+		return false, nil, nil
+	})
+	return &calls
+}
+
+func TestPatchNumatopoDespiteConcurrentWrites(t *testing.T) {
+	const nodeName = "node-c"
+	client := fake.NewSimpleClientset(&nodeinfov1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            nodeName,
+			ResourceVersion: "2",
+			Labels:          map[string]string{"written-by": "another-controller"},
+		},
+		Spec: nodeinfov1alpha1.NumatopoSpec{
+			Policies: map[nodeinfov1alpha1.PolicyName]string{nodeinfov1alpha1.CPUManagerPolicy: "stale"},
+		},
+	})
+
+	// the cached object misses the write of the other controller
+	stale := &nodeinfov1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, ResourceVersion: "1"},
+	}
+	e := newTestExporter(t, nodeName, WithNumatopoClient(client))
+	if err := e.CreateOrUpdateNumatopo(stale); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" {
+			t.Fatalf("expected the object not to be read again")
+		}
+	}
+
+	updated, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get Numatopology: %v", err)
+	}
+	if updated.Spec.Policies[nodeinfov1alpha1.CPUManagerPolicy] == "stale" {
+		t.Fatalf("expected the spec to be updated")
+	}
+	if updated.Labels["written-by"] != "another-controller" {
+		t.Fatalf("expected the write of the other controller to be kept, got labels %v", updated.Labels)
+	}
+}
+
+func TestCreateMergePatchOnlyChangedFields(t *testing.T) {
+	original := &nodeinfov1alpha1.Numatopology{ObjectMeta: metav1.ObjectMeta{Name: "node", ResourceVersion: "5"}}
+	modified := original.DeepCopy()
+	modified.Annotations = map[string]string{GenerationAnnotation: "1"}
+
+	patch, err := createMergePatch(original, modified)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"annotations":{"volcano.sh/numatopo-generation":"1"}}}`
+	if string(patch) != expected {
+		t.Fatalf("expected patch %s, got %s", expected, patch)
+	}
+}
+
+func TestPublisherRetriesFailedWrites(t *testing.T) {
+	const nodeName = "node-d"
+	client := fake.NewSimpleClientset()
+	creates := failFirst(client, "create", 1, apierrors.NewInternalError(context.DeadlineExceeded))
+
+	// an empty cache, as before the first Numatopology of the node is created
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	numaCache := &NumatopoCache{lister: listers.NewNumatopologyLister(indexer), nodeName: nodeName}
+
+	publisher := NewPublisher(newTestExporter(t, nodeName, WithNumatopoClient(client)), numaCache)
+	defer publisher.queue.ShutDown()
+	publisher.Enqueue()
+
+	publisher.processNextItem()
+	status := publisher.Status()
+	if !status.Pending || status.Failures != 1 || status.LastError == nil {
+		t.Fatalf("expected a pending publish with one failure, got %+v", status)
+	}
+
+	// the failed write is retried from the queue without another Enqueue
+	publisher.processNextItem()
+	status = publisher.Status()
+	if status.Pending || status.Failures != 0 || status.LastError != nil || status.LastPublished.IsZero() {
+		t.Fatalf("expected the publish to succeed, got %+v", status)
+	}
+	if *creates != 2 {
+		t.Fatalf("expected 2 creates, got %d", *creates)
+	}
+	if _, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected Numatopology to be created: %v", err)
+	}
+}
+
+func TestPublisherReconcilesDrift(t *testing.T) {
+	const nodeName = "node-e"
+	client := fake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	numaCache := &NumatopoCache{lister: listers.NewNumatopologyLister(indexer), nodeName: nodeName}
+	publisher := NewPublisher(newTestExporter(t, nodeName, WithNumatopoClient(client)), numaCache)
+	defer publisher.queue.ShutDown()
+
+	get := func() *nodeinfov1alpha1.Numatopology {
+		obj, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get Numatopology: %v", err)
+		}
+		return obj
+	}
+
+	publisher.Enqueue()
+	publisher.processNextItem()
+	published := get()
+	if err := indexer.Add(published); err != nil {
+		t.Fatalf("failed to add to cache: %v", err)
+	}
+
+	// our own write and an unchanged local state are not queued again
+	publisher.OnNumatopoChange(published)
+	publisher.Enqueue()
+	if publisher.queue.Len() != 0 {
+		t.Fatalf("expected nothing queued when the object matches the local state")
+	}
+
+	// an edit by someone else is reverted
+	edited := published.DeepCopy()
+	edited.Spec.Policies = map[nodeinfov1alpha1.PolicyName]string{nodeinfov1alpha1.CPUManagerPolicy: "edited"}
+	if _, err := client.NodeinfoV1alpha1().Numatopologies().Update(context.TODO(), edited, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to edit Numatopology: %v", err)
+	}
+	if err := indexer.Update(edited); err != nil {
+		t.Fatalf("failed to update cache: %v", err)
+	}
+	publisher.OnNumatopoChange(edited)
+	if publisher.queue.Len() != 1 {
+		t.Fatalf("expected the edited object to be queued for reconciliation")
+	}
+	publisher.processNextItem()
+	if got := get().Spec.Policies[nodeinfov1alpha1.CPUManagerPolicy]; got == "edited" {
+		t.Fatalf("expected the edit to be reverted")
+	}
+
+	// so is a deletion
+	publisher.OnNumatopoChange(nil)
+	if publisher.queue.Len() != 1 {
+		t.Fatalf("expected the deleted object to be queued for reconciliation")
+	}
+}
+
+func TestPublisherHoldsBackOnlyTheSpec(t *testing.T) {
+	const nodeName = "node-h"
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	numaCache := &NumatopoCache{lister: listers.NewNumatopologyLister(indexer), nodeName: nodeName}
+	e := newTestExporter(t, nodeName, WithNumatopoClient(fake.NewSimpleClientset()))
+	e.guard.holdCycles = 1
+	e.guard.getMachineInfo = func() *machineinfov1.MachineInfo { return nil }
+	publisher := NewPublisher(e, numaCache)
+	defer publisher.queue.ShutDown()
+
+	// the local state of the test exporter is empty, a regression of the spec published so far
+	last := guardSpec([]int{0, 1}, "0-1")
+	publisher.desired = &Snapshot{Numatopology: &nodeinfov1alpha1.Numatopology{Spec: *last}}
+	e.updateConditions(e.clock.Now(), time.Minute, nil, fmt.Errorf("get cpu detail failed"))
+	publisher.Enqueue()
+
+	if publisher.queue.Len() != 1 {
+		t.Fatalf("expected the changed conditions to be queued despite the held back regression")
+	}
+	desired := publisher.desired.Numatopology
+	if !equality.Semantic.DeepEqual(desired.Spec, *last) {
+		t.Fatalf("expected the spec published so far to be kept, got %+v", desired.Spec)
+	}
+	if !strings.Contains(desired.Annotations[ConditionsAnnotation], reasonCollectionFailed) {
+		t.Fatalf("expected the conditions annotation to be updated, got %q", desired.Annotations[ConditionsAnnotation])
+	}
+}
+
+type fakeSink struct {
+	failures  int
+	published []*Snapshot
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Publish(_ context.Context, snapshot *Snapshot) error {
+	if s.failures > 0 {
+		s.failures--
+		return fmt.Errorf("unavailable")
+	}
+	s.published = append(s.published, snapshot)
+	return nil
+}
+
+func TestPublisherRetriesFailedSinks(t *testing.T) {
+	const nodeName = "node-g"
+	client := fake.NewSimpleClientset()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	numaCache := &NumatopoCache{lister: listers.NewNumatopologyLister(indexer), nodeName: nodeName}
+	publisher := NewPublisher(newTestExporter(t, nodeName, WithNumatopoClient(client)), numaCache)
+	defer publisher.queue.ShutDown()
+	sink := &fakeSink{failures: 1}
+	publisher.AddSink(sink)
+
+	publisher.Enqueue()
+	publisher.processNextItem()
+	if status := publisher.Status(); !status.Pending || status.Failures != 1 {
+		t.Fatalf("expected the failed sink to keep the state pending, got %+v", status)
+	}
+	// the Numatopology is written regardless of the sink
+	if _, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected Numatopology to be created: %v", err)
+	}
+
+	publisher.processNextItem()
+	if status := publisher.Status(); status.Pending || status.Failures != 0 {
+		t.Fatalf("expected the state to be published, got %+v", status)
+	}
+	if len(sink.published) != 1 || sink.published[0].TopologyManagerScope != publisher.exporter.GetTopologyManagerScope() {
+		t.Fatalf("expected the snapshot to be published to the sink once, got %v", sink.published)
+	}
+}
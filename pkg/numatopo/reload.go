@@ -0,0 +1,166 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+// reloadableOptions are the fields of args.Argument which take effect without a restart,
+// the others are only read when the Exporter starts
+var reloadableOptions = sets.New(
+	"CheckInterval",
+	"ResyncPeriod",
+	"HeartbeatPeriod",
+	"ResReserved",
+	"Providers",
+	"ProviderUpdateTimeout",
+	"RegressionHoldCycles",
+)
+
+// WithConfigLoader reloads the options with loader when its configuration file changes or Reload is called
+func WithConfigLoader(loader *args.ConfigLoader) Option {
+	return func(e *Exporter) {
+		e.configLoader = loader
+	}
+}
+
+// Reload schedules reloading the configuration file of the config loader, e.g. on SIGHUP.
+// It does nothing without a config loader.
+func (e *Exporter) Reload() {
+	select {
+	case e.reloads <- struct{}{}:
+	default:
+	}
+}
+
+// reloadConfig loads the configuration again and applies the reloadable options which changed.
+// The options which need a restart are reported and otherwise ignored, an invalid configuration
+// is reported and the previous one is kept. It must be called from the refresh loop.
+func (e *Exporter) reloadConfig() {
+	if e.configLoader == nil {
+		return
+	}
+
+	loaded, err := e.configLoader.Load()
+	if err != nil {
+		klog.Errorf("Failed to reload the configuration, keeping the previous one: %v", err)
+		e.recordNodeEvent(v1.EventTypeWarning, ReasonConfigReloadFailed, "Keeping the previous configuration: %v", err)
+		return
+	}
+	// the options overridden by Options are not taken from the file, so they are no change either
+	for _, override := range e.overrides {
+		override(loaded)
+	}
+
+	// the options are replaced rather than modified, as provider updates which timed out may still read them
+	next := e.opt.DeepCopy()
+	var changed, restart []string
+	loadedValue, currentValue, startupValue := reflect.ValueOf(loaded).Elem(), reflect.ValueOf(e.opt).Elem(), reflect.ValueOf(e.startupOpt).Elem()
+	nextValue := reflect.ValueOf(next).Elem()
+	for i := 0; i < loadedValue.NumField(); i++ {
+		name := loadedValue.Type().Field(i).Name
+		value := loadedValue.Field(i).Interface()
+		switch {
+		case reloadableOptions.Has(name):
+			if !reflect.DeepEqual(value, currentValue.Field(i).Interface()) {
+				nextValue.Field(i).Set(loadedValue.Field(i))
+				changed = append(changed, name)
+			}
+		case !reflect.DeepEqual(value, startupValue.Field(i).Interface()):
+			restart = append(restart, name)
+		}
+	}
+
+	if !reflect.DeepEqual(restart, e.restartRequired) {
+		if len(restart) > 0 {
+			klog.Warningf("Options %v changed in the configuration, they take effect after a restart", restart)
+			e.recordNodeEvent(v1.EventTypeWarning, ReasonConfigReloaded, "Options %s changed in the configuration, they take effect after a restart", strings.Join(restart, ", "))
+		}
+		e.restartRequired = restart
+	}
+	if len(changed) == 0 {
+		klog.V(4).Infof("Configuration reloaded, nothing changed")
+		return
+	}
+
+	previous := e.opt
+	e.opt = next
+	if !reflect.DeepEqual(next.Providers, previous.Providers) {
+		if err := e.registerProviders(); err != nil {
+			e.opt = previous
+			klog.Errorf("Failed to reload the providers, keeping the previous configuration: %v", err)
+			e.recordNodeEvent(v1.EventTypeWarning, ReasonConfigReloadFailed, "Keeping the previous configuration: %v", err)
+			return
+		}
+	}
+	e.guard.holdCycles = next.RegressionHoldCycles
+
+	klog.Infof("Configuration reloaded, changed options: %v", changed)
+	e.recordNodeEvent(v1.EventTypeNormal, ReasonConfigReloaded, "Reloaded options %s", strings.Join(changed, ", "))
+}
+
+// watchConfigFile calls Reload when the configuration file at path changes until ctx is done.
+// The directory is watched, as a mounted ConfigMap is updated by replacing a symlink next to the file.
+func (e *Exporter) watchConfigFile(ctx context.Context, path string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+	klog.V(2).Infof("Watching %s for changes of the configuration", dir)
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// any change of the directory may be the symlink swap, unchanged files are not applied again
+				if !event.Has(fsnotify.Chmod) {
+					klog.V(4).Infof("Configuration directory changed: %s", event)
+					e.Reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				klog.Warningf("Configuration watch error: %v", err)
+				e.Reload()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+const testConfigHeader = "apiVersion: " + args.ConfigurationAPIVersion + "\nkind: " + args.ConfigurationKind + "\n"
+
+// newReloadingExporter creates an Exporter with options whose Argument is loaded from the
+// configuration file at path with content, and whose Events are kept for recordedEvents
+func newReloadingExporter(t *testing.T, content string, options ...Option) (*Exporter, string, *record.FakeRecorder) {
+	t.Helper()
+	root := t.TempDir()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), content)
+
+	flags := args.NewArgument()
+	flags.NodeName = "node-a"
+	flags.HostRoot = root
+	flags.ConfigFile = path
+	loader := args.NewConfigLoader(flags, nil)
+	opt, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	fake := record.NewFakeRecorder(10)
+	e, err := NewExporter(opt, append([]Option{WithConfigLoader(loader), WithEventRecorder(fake)}, options...)...)
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+	return e, path, fake
+}
+
+func TestReloadConfig(t *testing.T) {
+	e, path, fake := newReloadingExporter(t, testConfigHeader+"checkPeriod: 5s\n")
+	if e.opt.CheckInterval != 5*time.Second || e.guard.holdCycles != 3 {
+		t.Fatalf("expected the options of the file, got check period %v and hold cycles %d", e.opt.CheckInterval, e.guard.holdCycles)
+	}
+
+	// unchanged options are not reported
+	e.reloadConfig()
+	if events := recordedEvents(fake); len(events) != 0 {
+		t.Fatalf("expected no Event without changes, got %v", events)
+	}
+
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), testConfigHeader+`checkPeriod: 1s
+reservation:
+  reserved:
+    cpu: "1"
+publish:
+  regressionHoldCycles: 5
+  nrt: true
+`)
+	cpuManagerState := e.opt.CPUMngState
+	e.reloadConfig()
+	if e.opt.CheckInterval != time.Second || !reflect.DeepEqual(e.opt.ResReserved, map[string]string{"cpu": "1"}) || e.guard.holdCycles != 5 {
+		t.Fatalf("expected the reloaded options, got check period %v, reservation %v and hold cycles %d", e.opt.CheckInterval, e.opt.ResReserved, e.guard.holdCycles)
+	}
+	if e.opt.PublishNRT || e.opt.CPUMngState != cpuManagerState {
+		t.Fatalf("expected the options which need a restart to be kept, got %+v", e.opt)
+	}
+	events := recordedEvents(fake)
+	if len(events) != 2 || !strings.Contains(events[0], "PublishNRT") || !strings.Contains(events[1], "Reloaded options CheckInterval, ResReserved, RegressionHoldCycles") {
+		t.Fatalf("expected Events about the restart and the reloaded options, got %v", events)
+	}
+}
+
+func TestReloadConfigKeepsOptionOverrides(t *testing.T) {
+	root := t.TempDir()
+	e, _, fake := newReloadingExporter(t, testConfigHeader+"checkPeriod: 5s\n", WithHostRoot(root))
+	if e.opt.HostRoot != root {
+		t.Fatalf("expected the host root %s of the Option, got %s", root, e.opt.HostRoot)
+	}
+
+	e.reloadConfig()
+	if len(e.restartRequired) != 0 || e.opt.HostRoot != root {
+		t.Fatalf("expected the host root of the Option to be kept without a restart, got %s and %v", e.opt.HostRoot, e.restartRequired)
+	}
+	if events := recordedEvents(fake); len(events) != 0 {
+		t.Fatalf("expected no Event without changes, got %v", events)
+	}
+}
+
+func TestReloadConfigInvalid(t *testing.T) {
+	e, path, fake := newReloadingExporter(t, testConfigHeader+"checkPeriod: 5s\n")
+
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), testConfigHeader+"checkPeriod: 1s\nunknownOption: true\n")
+	e.reloadConfig()
+	if e.opt.CheckInterval != 5*time.Second {
+		t.Fatalf("expected the previous options to be kept, got check period %v", e.opt.CheckInterval)
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonConfigReloadFailed) {
+		t.Fatalf("expected a %s Event, got %v", ReasonConfigReloadFailed, events)
+	}
+
+	// an unknown provider fails the whole reload
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), testConfigHeader+"checkPeriod: 1s\nproviders:\n  enabled: [cpu, gpu]\n")
+	e.reloadConfig()
+	if e.opt.CheckInterval != 5*time.Second || !reflect.DeepEqual(e.opt.Providers, []string{resourceCPU}) {
+		t.Fatalf("expected the previous options to be kept, got check period %v and providers %v", e.opt.CheckInterval, e.opt.Providers)
+	}
+	if events := recordedEvents(fake); len(events) != 1 || !strings.Contains(events[0], ReasonConfigReloadFailed) {
+		t.Fatalf("expected a %s Event, got %v", ReasonConfigReloadFailed, events)
+	}
+}
+
+func TestReloadConfigProviders(t *testing.T) {
+	e, path, _ := newReloadingExporter(t, testConfigHeader)
+	e.factories["fake"] = providerFactory{create: func(_ *Exporter) NumaInfo { return &fakeProvider{name: "fake"} }}
+	cpu := e.providers[resourceCPU]
+
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), testConfigHeader+"providers:\n  enabled: [cpu, fake]\n")
+	e.reloadConfig()
+	if got := sets.List(sets.KeySet(e.providers)); !reflect.DeepEqual(got, []string{resourceCPU, "fake"}) {
+		t.Fatalf("expected the fake provider to be registered, got %v", got)
+	}
+	if e.providers[resourceCPU] != cpu {
+		t.Fatalf("expected the cpu provider to keep its snapshot")
+	}
+
+	writeHostFile(t, filepath.Dir(path), filepath.Base(path), testConfigHeader+"providers:\n  enabled: [fake]\n")
+	e.reloadConfig()
+	if got := sets.List(sets.KeySet(e.providers)); !reflect.DeepEqual(got, []string{"fake"}) {
+		t.Fatalf("expected the cpu provider to be removed, got %v", got)
+	}
+}
@@ -19,7 +19,6 @@ package numatopo
 import (
 	"context"
 	"fmt"
-	"os"
 	"testing"
 
 	machineinfov1 "github.com/google/cadvisor/info/v1"
@@ -84,10 +83,35 @@ func TestReservationCalculation(t *testing.T) {
 				v1.ResourceMemory: fmt.Sprintf("%dMi", 2048+1324),
 			},
 		},
+		{
+			kubeletconfigv1beta1.KubeletConfiguration{
+				KubeReserved: map[string]string{
+					string(v1.ResourceCPU):              "500m",
+					string(v1.ResourceEphemeralStorage): "1Gi",
+					"pid":                               "1000",
+				},
+				EvictionHard: map[string]string{
+					"memory.available": "100Mi",
+					"pid.available":    "100",
+				},
+				EvictionSoft: map[string]string{
+					"memory.available": "500Mi",
+				},
+				EvictionSoftGracePeriod: map[string]string{
+					"memory.available": "1m",
+				},
+			},
+			map[v1.ResourceName]string{
+				v1.ResourceCPU:              "500m",
+				v1.ResourceMemory:           "500Mi",
+				v1.ResourceEphemeralStorage: "1Gi",
+				"pid":                       "1100",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
-		reservation, err := calculateNodeResourceReservation(tc.kletConfg.KubeReserved, tc.kletConfg.SystemReserved, tc.kletConfg.EvictionHard, &machineInfo)
+		reservation, err := calculateNodeResourceReservation(&tc.kletConfg, &machineInfo)
 		if err != nil {
 			t.Error(err)
 			return
@@ -109,18 +133,6 @@ func TestReservationCalculation(t *testing.T) {
 func TestCreateOrUpdateNumatopoUpdatesExistingResourceWhenCreateAlreadyExists(t *testing.T) {
 	const nodeName = "node-a"
 
-	oldNodeName, hadNodeName := os.LookupEnv("MY_NODE_NAME")
-	if err := os.Setenv("MY_NODE_NAME", nodeName); err != nil {
-		t.Fatalf("failed to set MY_NODE_NAME: %v", err)
-	}
-	defer func() {
-		if hadNodeName {
-			_ = os.Setenv("MY_NODE_NAME", oldNodeName)
-		} else {
-			_ = os.Unsetenv("MY_NODE_NAME")
-		}
-	}()
-
 	client := fake.NewSimpleClientset(&nodeinfov1alpha1.Numatopology{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: nodeName,
@@ -132,16 +144,153 @@ func TestCreateOrUpdateNumatopoUpdatesExistingResourceWhenCreateAlreadyExists(t
 		},
 	})
 
-	CreateOrUpdateNumatopo(client, nil)
+	e := newTestExporter(t, nodeName, WithNumatopoClient(client))
+	if err := e.CreateOrUpdateNumatopo(nil); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
 
 	updated, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
 	if err != nil {
 		t.Fatalf("failed to get updated Numatopology: %v", err)
 	}
-	if updated.Annotations["timestamp"] == "" {
-		t.Fatalf("expected timestamp annotation to be set after update")
+	if updated.Annotations[GenerationAnnotation] == "" {
+		t.Fatalf("expected generation annotation to be set after update")
 	}
 	if updated.Spec.Policies[nodeinfov1alpha1.CPUManagerPolicy] == "stale" {
 		t.Fatalf("expected existing Numatopology spec to be updated")
 	}
 }
+
+func TestCreateOrUpdateNumatopoPatchesWithGeneration(t *testing.T) {
+	const nodeName = "node-b"
+	client := fake.NewSimpleClientset(&nodeinfov1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+			Annotations: map[string]string{
+				"timestamp":          "1700000000",
+				GenerationAnnotation: "41",
+				"other-controller":   "keep",
+			},
+		},
+	})
+	get := func() *nodeinfov1alpha1.Numatopology {
+		obj, err := client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get Numatopology: %v", err)
+		}
+		return obj
+	}
+	e := newTestExporter(t, nodeName, WithNumatopoClient(client))
+
+	if err := e.CreateOrUpdateNumatopo(get()); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+	updated := get()
+	if updated.Annotations[GenerationAnnotation] != "42" {
+		t.Fatalf("expected generation 42, got %q", updated.Annotations[GenerationAnnotation])
+	}
+	if _, ok := updated.Annotations["timestamp"]; ok {
+		t.Fatalf("expected the legacy timestamp annotation to be removed")
+	}
+	if updated.Annotations["other-controller"] != "keep" {
+		t.Fatalf("expected annotations of other controllers to be kept")
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			t.Fatalf("expected a patch instead of a full update")
+		}
+	}
+
+	// nothing changed locally: no write and the generation stays
+	client.ClearActions()
+	if err := e.CreateOrUpdateNumatopo(updated); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Fatalf("expected no write when the object is up to date")
+		}
+	}
+
+	// a stale cache must not make the generation go backwards
+	stale := updated.DeepCopy()
+	stale.Annotations[GenerationAnnotation] = "41"
+	stale.Spec.Policies = map[nodeinfov1alpha1.PolicyName]string{nodeinfov1alpha1.CPUManagerPolicy: "stale"}
+	if err := e.CreateOrUpdateNumatopo(stale); err != nil {
+		t.Fatalf("CreateOrUpdateNumatopo failed: %v", err)
+	}
+	if got := get().Annotations[GenerationAnnotation]; got != "43" {
+		t.Fatalf("expected generation 43, got %q", got)
+	}
+}
+
+func TestCalculateNodeStatusReservation(t *testing.T) {
+	capacity := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("8"),
+		v1.ResourceMemory: resource.MustParse("16Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("7500m"),
+		v1.ResourceMemory: resource.MustParse("15Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+
+	reservation := calculateNodeStatusReservation(capacity, allocatable)
+	if got := reservation[v1.ResourceCPU]; got.Cmp(resource.MustParse("500m")) != 0 {
+		t.Errorf("cpu: expect 500m, got %v", got.String())
+	}
+	if got := reservation[v1.ResourceMemory]; got.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("memory: expect 1Gi, got %v", got.String())
+	}
+	if _, ok := reservation[v1.ResourcePods]; ok {
+		t.Errorf("pods: zero reservation must be omitted")
+	}
+}
+
+func TestTryUpdatingResourceReservationSource(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("8"),
+				v1.ResourceMemory: resource.MustParse("16Gi"),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("7"),
+				v1.ResourceMemory: resource.MustParse("15Gi"),
+				v1.ResourcePods:   resource.MustParse("100"),
+			},
+		},
+	}
+	e.getNode = func() (*v1.Node, error) { return node, nil }
+	klConfig := &kubeletconfigv1beta1.KubeletConfiguration{}
+
+	if !e.TryUpdatingResourceReservation(klConfig, nil) {
+		t.Fatalf("expected a change on first update")
+	}
+	if e.GetReservationSource() != ReservationSourceNodeStatus || e.GetResReserved()[string(v1.ResourceCPU)] != "1" {
+		t.Fatalf("expected cpu=1 from node status, got %v from %q", e.GetResReserved(), e.GetReservationSource())
+	}
+	if _, ok := e.GetResReserved()[string(v1.ResourcePods)]; ok {
+		t.Fatalf("pods is not a reservable resource, got %v", e.GetResReserved())
+	}
+
+	if !e.TryUpdatingResourceReservation(klConfig, map[string]string{string(v1.ResourceCPU): "2"}) {
+		t.Fatalf("expected a change when --res-reserved is given")
+	}
+	if e.GetReservationSource() != ReservationSourceFlag || e.GetResReserved()[string(v1.ResourceCPU)] != "2" {
+		t.Fatalf("expected cpu=2 from flag, got %v from %q", e.GetResReserved(), e.GetReservationSource())
+	}
+	if e.GetResReserved()[string(v1.ResourceMemory)] != "1Gi" {
+		t.Fatalf("expected memory=1Gi from node status alongside the flag, got %v", e.GetResReserved())
+	}
+
+	e.getNode = func() (*v1.Node, error) { return nil, fmt.Errorf("forbidden") }
+	e.TryUpdatingResourceReservation(klConfig, nil)
+	if e.GetReservationSource() != ReservationSourceKubeletConfig {
+		t.Fatalf("expected fallback to kubelet config, got %q", e.GetReservationSource())
+	}
+}
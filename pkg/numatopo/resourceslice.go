@@ -0,0 +1,242 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	resourcev1 "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// the device attributes, qualified by the driver name
+const (
+	draAttributeCPUID     = "cpuID"
+	draAttributeNUMANode  = "numaNode"
+	draAttributeSocketID  = "socketID"
+	draAttributeCoreID    = "coreID"
+	draAttributeL3CacheID = "l3CacheID"
+)
+
+// ResourceSliceSink publishes every CPU of the node kubelet does not reserve as a device of a Dynamic
+// Resource Allocation ResourceSlice, with its NUMA node, socket, core and L3 cache domain as attributes,
+// so claims can select CPUs of the same NUMA node or cache domain. Devices are ordered by NUMA node,
+// socket and L3 cache domain; nodes with more CPUs than a slice holds get several slices in the same pool.
+// The slices list capacity rather than availability: the CPUs exclusively allocated by kubelet are
+// not excluded, so the slices do not change with every pod.
+type ResourceSliceSink struct {
+	client   kubernetes.Interface
+	nodeName string
+	driver   string
+}
+
+// NewResourceSliceSink creates a ResourceSliceSink writing the ResourceSlices of nodeName for driver
+func NewResourceSliceSink(client kubernetes.Interface, nodeName, driver string) *ResourceSliceSink {
+	return &ResourceSliceSink{
+		client:   client,
+		nodeName: nodeName,
+		driver:   driver,
+	}
+}
+
+// Name implements Sink
+func (s *ResourceSliceSink) Name() string {
+	return "ResourceSlice"
+}
+
+// Publish implements Sink. If any slice of the pool changes, the pool generation is increased and
+// every slice is written again, as consumers only use the slices of the latest generation.
+func (s *ResourceSliceSink) Publish(ctx context.Context, snapshot *Snapshot) error {
+	desired := s.resourceSlices(snapshot)
+	sliceClient := s.client.ResourceV1().ResourceSlices()
+
+	list, err := sliceClient.List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			resourcev1.ResourceSliceSelectorNodeName: s.nodeName,
+			resourcev1.ResourceSliceSelectorDriver:   s.driver,
+		}.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*resourcev1.ResourceSlice, len(list.Items))
+	var generation int64
+	for i := range list.Items {
+		slice := &list.Items[i]
+		if slice.Spec.Driver != s.driver || slice.Spec.NodeName == nil || *slice.Spec.NodeName != s.nodeName {
+			continue
+		}
+		existing[slice.Name] = slice
+		if slice.Spec.Pool.Generation > generation {
+			generation = slice.Spec.Pool.Generation
+		}
+	}
+	if resourceSlicesUpToDate(existing, desired, generation) {
+		klog.V(4).Infof("ResourceSlices of node %s are up to date, skip updating", s.nodeName)
+		return nil
+	}
+
+	generation++
+	for _, slice := range desired {
+		slice.Spec.Pool.Generation = generation
+		current, ok := existing[slice.Name]
+		if !ok {
+			if _, err = sliceClient.Create(ctx, slice, metav1.CreateOptions{FieldManager: FieldManager}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		delete(existing, slice.Name)
+		updated := current.DeepCopy()
+		updated.Spec = slice.Spec
+		for _, owner := range slice.OwnerReferences {
+			updated.OwnerReferences = replaceNodeOwner(updated.OwnerReferences, owner)
+		}
+		if _, err = sliceClient.Update(ctx, updated, metav1.UpdateOptions{FieldManager: FieldManager}); err != nil {
+			return err
+		}
+	}
+	for name := range existing {
+		err = sliceClient.Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	klog.V(4).Infof("Published %d ResourceSlice(s) of node %s with pool generation %d", len(desired), s.nodeName, generation)
+	return nil
+}
+
+// resourceSlicesUpToDate returns true if existing has exactly the desired slices at generation
+func resourceSlicesUpToDate(existing map[string]*resourcev1.ResourceSlice, desired []*resourcev1.ResourceSlice, generation int64) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for _, slice := range desired {
+		current, ok := existing[slice.Name]
+		if !ok || current.Spec.Pool.Generation != generation {
+			return false
+		}
+		spec := slice.Spec.DeepCopy()
+		spec.Pool.Generation = generation
+		if !equality.Semantic.DeepEqual(current.Spec, *spec) {
+			return false
+		}
+		for _, owner := range slice.OwnerReferences {
+			if !equality.Semantic.DeepEqual(current.OwnerReferences, replaceNodeOwner(current.OwnerReferences, owner)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resourceSlices translates snapshot into the ResourceSlices of the node, without pool generation
+func (s *ResourceSliceSink) resourceSlices(snapshot *Snapshot) []*resourcev1.ResourceSlice {
+	devices := s.cpuDevices(snapshot)
+
+	// a node without CPUs still gets one empty slice, so the pool is known to be empty
+	var slices []*resourcev1.ResourceSlice
+	for start := 0; start < len(devices) || start == 0; start += resourcev1.ResourceSliceMaxDevices {
+		end := start + resourcev1.ResourceSliceMaxDevices
+		if end > len(devices) {
+			end = len(devices)
+		}
+		slices = append(slices, &resourcev1.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            fmt.Sprintf("%s-%s-%d", s.nodeName, s.driver, len(slices)),
+				OwnerReferences: snapshot.Numatopology.OwnerReferences,
+			},
+			Spec: resourcev1.ResourceSliceSpec{
+				Driver:   s.driver,
+				Pool:     resourcev1.ResourcePool{Name: s.nodeName},
+				NodeName: ptr.To(s.nodeName),
+				Devices:  devices[start:end],
+			},
+		})
+	}
+	for _, slice := range slices {
+		slice.Spec.Pool.ResourceSliceCount = int64(len(slices))
+	}
+
+	return slices
+}
+
+func (s *ResourceSliceSink) cpuDevices(snapshot *Snapshot) []resourcev1.Device {
+	type cpuKey struct {
+		numa, socket, l3Cache, core, cpu int
+	}
+	reserved := reservedCPUs(snapshot.Numatopology.Spec, snapshot.ReservedSystemCPUs)
+	var cpus []cpuKey
+	for id, info := range snapshot.Numatopology.Spec.CPUDetail {
+		cpuID, err := strconv.Atoi(id)
+		if err != nil || reserved.Has(cpuID) {
+			continue
+		}
+		l3Cache, ok := snapshot.CPUL3Cache[id]
+		if !ok {
+			l3Cache = -1
+		}
+		cpus = append(cpus, cpuKey{numa: info.NUMANodeID, socket: info.SocketID, l3Cache: l3Cache, core: info.CoreID, cpu: cpuID})
+	}
+	sort.Slice(cpus, func(i, j int) bool {
+		a, b := cpus[i], cpus[j]
+		if a.numa != b.numa {
+			return a.numa < b.numa
+		}
+		if a.socket != b.socket {
+			return a.socket < b.socket
+		}
+		if a.l3Cache != b.l3Cache {
+			return a.l3Cache < b.l3Cache
+		}
+		if a.core != b.core {
+			return a.core < b.core
+		}
+		return a.cpu < b.cpu
+	})
+
+	devices := make([]resourcev1.Device, 0, len(cpus))
+	for _, cpu := range cpus {
+		attributes := map[resourcev1.QualifiedName]resourcev1.DeviceAttribute{
+			draAttributeCPUID:    {IntValue: ptr.To(int64(cpu.cpu))},
+			draAttributeNUMANode: {IntValue: ptr.To(int64(cpu.numa))},
+			draAttributeSocketID: {IntValue: ptr.To(int64(cpu.socket))},
+			draAttributeCoreID:   {IntValue: ptr.To(int64(cpu.core))},
+		}
+		if cpu.l3Cache >= 0 {
+			attributes[draAttributeL3CacheID] = resourcev1.DeviceAttribute{IntValue: ptr.To(int64(cpu.l3Cache))}
+		}
+		devices = append(devices, resourcev1.Device{
+			Name:       fmt.Sprintf("cpu-%d", cpu.cpu),
+			Attributes: attributes,
+		})
+	}
+
+	return devices
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	resourcev1 "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	nodeinfov1alpha1 "volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// sliceSnapshot returns a snapshot of numCPUs cpus on two NUMA nodes with one L3 cache each
+func sliceSnapshot(numCPUs int) *Snapshot {
+	snapshot := &Snapshot{
+		Numatopology: &nodeinfov1alpha1.Numatopology{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: "node-a", UID: "uid-a"}},
+			},
+			Spec: nodeinfov1alpha1.NumatopoSpec{CPUDetail: map[string]nodeinfov1alpha1.CPUInfo{}},
+		},
+		CPUL3Cache: map[string]int{},
+	}
+	for cpu := 0; cpu < numCPUs; cpu++ {
+		// cpus are interleaved between the NUMA nodes
+		numa := cpu % 2
+		snapshot.Numatopology.Spec.CPUDetail[strconv.Itoa(cpu)] = nodeinfov1alpha1.CPUInfo{NUMANodeID: numa, SocketID: numa, CoreID: cpu / 2}
+		snapshot.CPUL3Cache[strconv.Itoa(cpu)] = numa * 8
+	}
+	return snapshot
+}
+
+func TestResourceSliceDevices(t *testing.T) {
+	sink := NewResourceSliceSink(nil, "node-a", "cpu.volcano.sh")
+	snapshot := sliceSnapshot(4)
+	delete(snapshot.CPUL3Cache, "1")
+
+	slices := sink.resourceSlices(snapshot)
+	if len(slices) != 1 {
+		t.Fatalf("expected 1 slice, got %d", len(slices))
+	}
+	slice := slices[0]
+	if slice.Name != "node-a-cpu.volcano.sh-0" || slice.Spec.Driver != "cpu.volcano.sh" || *slice.Spec.NodeName != "node-a" ||
+		slice.Spec.Pool.Name != "node-a" || slice.Spec.Pool.ResourceSliceCount != 1 {
+		t.Fatalf("unexpected slice %s: %+v", slice.Name, slice.Spec)
+	}
+	if len(slice.OwnerReferences) != 1 || slice.OwnerReferences[0].Kind != "Node" {
+		t.Fatalf("expected the Node owner, got %v", slice.OwnerReferences)
+	}
+
+	// grouped by NUMA node
+	var names []string
+	for _, device := range slice.Spec.Devices {
+		names = append(names, device.Name)
+	}
+	expectedNames := []string{"cpu-0", "cpu-2", "cpu-1", "cpu-3"}
+	for i := range expectedNames {
+		if names[i] != expectedNames[i] {
+			t.Fatalf("expected devices %v, got %v", expectedNames, names)
+		}
+	}
+
+	attributes := slice.Spec.Devices[1].Attributes
+	for name, expected := range map[resourcev1.QualifiedName]int64{"cpuID": 2, "numaNode": 0, "socketID": 0, "coreID": 1, "l3CacheID": 0} {
+		if attributes[name].IntValue == nil || *attributes[name].IntValue != expected {
+			t.Errorf("expected attribute %s=%d, got %v", name, expected, attributes[name])
+		}
+	}
+	if _, ok := slice.Spec.Devices[2].Attributes["l3CacheID"]; ok {
+		t.Errorf("expected no l3CacheID for a cpu without L3 cache")
+	}
+}
+
+func TestResourceSliceReservedCPUs(t *testing.T) {
+	sink := NewResourceSliceSink(nil, "node-a", "cpu.volcano.sh")
+	deviceNames := func(snapshot *Snapshot) []string {
+		var names []string
+		for _, device := range sink.resourceSlices(snapshot)[0].Spec.Devices {
+			names = append(names, device.Name)
+		}
+		return names
+	}
+
+	snapshot := sliceSnapshot(4)
+	snapshot.ReservedSystemCPUs = "0-1"
+	if names := deviceNames(snapshot); !reflect.DeepEqual(names, []string{"cpu-2", "cpu-3"}) {
+		t.Errorf("expected the reservedSystemCPUs to be left out, got %v", names)
+	}
+
+	snapshot = sliceSnapshot(4)
+	snapshot.Numatopology.Spec.ResReserved = map[string]string{"cpu": "500m"}
+	if names := deviceNames(snapshot); !reflect.DeepEqual(names, []string{"cpu-2", "cpu-1", "cpu-3"}) {
+		t.Errorf("expected the reserved cpu to be left out, got %v", names)
+	}
+}
+
+func TestResourceSliceSinkPublish(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	sink := NewResourceSliceSink(client, "node-a", "cpu.volcano.sh")
+	list := func() map[string]resourcev1.ResourceSlice {
+		slices, err := client.ResourceV1().ResourceSlices().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to list ResourceSlices: %v", err)
+		}
+		result := make(map[string]resourcev1.ResourceSlice)
+		for _, slice := range slices.Items {
+			result[slice.Name] = slice
+		}
+		return result
+	}
+
+	// more cpus than a slice holds
+	if err := sink.Publish(context.TODO(), sliceSnapshot(resourcev1.ResourceSliceMaxDevices+2)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	slices := list()
+	if len(slices) != 2 {
+		t.Fatalf("expected 2 slices, got %d", len(slices))
+	}
+	for name, slice := range slices {
+		if slice.Spec.Pool.Generation != 1 || slice.Spec.Pool.ResourceSliceCount != 2 {
+			t.Fatalf("unexpected pool of %s: %+v", name, slice.Spec.Pool)
+		}
+	}
+
+	// unchanged: no write
+	client.ClearActions()
+	if err := sink.Publish(context.TODO(), sliceSnapshot(resourcev1.ResourceSliceMaxDevices+2)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "list" {
+			t.Fatalf("expected no write when up to date, got %s", action.GetVerb())
+		}
+	}
+
+	// fewer cpus: the pool shrinks to one slice of the next generation
+	if err := sink.Publish(context.TODO(), sliceSnapshot(4)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	slices = list()
+	slice, ok := slices["node-a-cpu.volcano.sh-0"]
+	if len(slices) != 1 || !ok {
+		t.Fatalf("expected only the first slice to be kept, got %d", len(slices))
+	}
+	if slice.Spec.Pool.Generation != 2 || slice.Spec.Pool.ResourceSliceCount != 1 || len(slice.Spec.Devices) != 4 {
+		t.Fatalf("unexpected slice after shrinking: pool %+v, %d devices", slice.Spec.Pool, len(slice.Spec.Devices))
+	}
+}
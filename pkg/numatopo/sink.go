@@ -0,0 +1,71 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// Snapshot is the local state taken by Publisher.Enqueue, it is written to the Numatopology and every Sink
+type Snapshot struct {
+	// Numatopology holds the spec, annotations and owner the Numatopology should have
+	Numatopology *v1alpha1.Numatopology
+	// TopologyManagerScope is the kubelet topology manager scope, which the Numatopology has no field for
+	TopologyManagerScope string
+	// ReservedSystemCPUs is the reservedSystemCPUs of kubelet, which the Numatopology has no field for
+	ReservedSystemCPUs string
+	// CPUL3Cache is the L3 cache domain of every cpu which has one, which the Numatopology has no field for
+	CPUL3Cache map[string]int
+	// PCIDevices is the NUMA locality of the PCI devices by address, which the Numatopology has no field for
+	PCIDevices map[string]PCIDevice
+}
+
+// Sink publishes the node topology for consumers which do not read the Numatopology
+type Sink interface {
+	// Name identifies the sink in logs
+	Name() string
+	// Publish writes snapshot, nothing should be written if the destination is up to date.
+	// An error makes the Publisher retry with backoff.
+	Publish(ctx context.Context, snapshot *Snapshot) error
+}
+
+// Snapshot returns a copy of the local state, which the collectors keep changing afterwards
+func (e *Exporter) Snapshot() *Snapshot {
+	return &Snapshot{
+		Numatopology:         e.desiredNumatopo(),
+		TopologyManagerScope: e.GetTopologyManagerScope(),
+		ReservedSystemCPUs:   e.GetReservedSystemCPUs(),
+		CPUL3Cache:           e.GetCPUL3CacheDetail(),
+		PCIDevices:           e.GetPCIDevices(),
+	}
+}
+
+// equal returns true if s and other hold the same state
+func (s *Snapshot) equal(other *Snapshot) bool {
+	return other != nil &&
+		equality.Semantic.DeepEqual(s.Numatopology.Spec, other.Numatopology.Spec) &&
+		reflect.DeepEqual(s.Numatopology.Annotations, other.Numatopology.Annotations) &&
+		s.TopologyManagerScope == other.TopologyManagerScope &&
+		s.ReservedSystemCPUs == other.ReservedSystemCPUs &&
+		reflect.DeepEqual(s.CPUL3Cache, other.CPUL3Cache) &&
+		reflect.DeepEqual(s.PCIDevices, other.PCIDevices)
+}
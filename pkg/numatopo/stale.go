@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// StaleAnnotation is the Numatopology annotation listing as JSON the parts of the topology which
+// could not be collected and are published with their last-known-good value
+const StaleAnnotation = "volcano.sh/numatopo-stale"
+
+// the reasons a part of the topology is stale
+const (
+	// StaleReasonReadFailed means the field could not be read or parsed
+	StaleReasonReadFailed = "ReadFailed"
+	// StaleReasonUpdateTimedOut means the update of the whole provider did not return in time
+	StaleReasonUpdateTimedOut = "UpdateTimedOut"
+)
+
+// StaleField is a part of the topology which could not be collected. Its last-known-good value is
+// published instead of the result of the failed read, so a read error never looks like a change.
+type StaleField struct {
+	Provider string `json:"provider"`
+	// Field is the stale part of the provider, empty if the whole provider is stale
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Since is when the field became stale
+	Since metav1.Time `json:"since"`
+}
+
+func (f *StaleField) key() string {
+	return f.Provider + "/" + f.Field + "/" + f.Reason
+}
+
+// String returns the field and why it is stale, for logs and condition messages
+func (f *StaleField) String() string {
+	name := f.Provider
+	if f.Field != "" {
+		name += "." + f.Field
+	}
+	return fmt.Sprintf("%s: %s", name, f.Message)
+}
+
+// newStaleField returns the StaleField of field of provider, which could not be read because of err
+func newStaleField(provider, field string, err error) StaleField {
+	return StaleField{Provider: provider, Field: field, Reason: StaleReasonReadFailed, Message: err.Error()}
+}
+
+// GetStaleFields returns the parts of the topology which are published with their last-known-good value
+func (e *Exporter) GetStaleFields() []StaleField {
+	return e.stale
+}
+
+// updateStaleFields collects the stale fields of all the providers, the fields which were stale
+// already keep the time they became stale. If the stale fields are changed, return true.
+func (e *Exporter) updateStaleFields(now time.Time) bool {
+	e.providerMutex.RLock()
+	var current []StaleField
+	for _, name := range sets.List(sets.KeySet(e.providers)) {
+		for _, field := range e.providers[name].GetStaleFields() {
+			field.Provider = name
+			current = append(current, field)
+		}
+	}
+	current = append(current, e.providerStale...)
+	e.providerMutex.RUnlock()
+
+	// every provider failing in this refresh is counted once per reason, however many of its fields are stale
+	failed := sets.New[string]()
+	for _, field := range current {
+		if key := field.Provider + "/" + field.Reason; !failed.Has(key) {
+			failed.Insert(key)
+			e.metrics.providerUpdateErrors.WithLabelValues(field.Provider, field.Reason).Inc()
+		}
+	}
+
+	previous := make(map[string]StaleField, len(e.stale))
+	for _, field := range e.stale {
+		previous[field.key()] = field
+	}
+	for i := range current {
+		field := &current[i]
+		if old, ok := previous[field.key()]; ok {
+			field.Since = old.Since
+			continue
+		}
+		field.Since = metav1.NewTime(now)
+		klog.Warningf("Topology is stale, publishing the last known value of %s", field)
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].key() < current[j].key() })
+
+	if len(current) == 0 && len(e.stale) == 0 || reflect.DeepEqual(current, e.stale) {
+		return false
+	}
+	if len(current) == 0 {
+		klog.Infof("Topology is up to date again")
+	}
+	e.stale = current
+	return true
+}
+
+// staleErr returns an error listing the stale fields, nil if none is stale
+func (e *Exporter) staleErr() error {
+	if len(e.stale) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(e.stale))
+	for i := range e.stale {
+		messages = append(messages, e.stale[i].String())
+	}
+	return fmt.Errorf("stale topology, the last known value is published for %s", strings.Join(messages, "; "))
+}
+
+// staleAnnotation returns the value of StaleAnnotation, empty if nothing is stale
+func (e *Exporter) staleAnnotation() string {
+	if len(e.stale) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(e.stale)
+	if err != nil {
+		klog.Errorf("Marshal stale fields failed, err: %v", err)
+		return ""
+	}
+	return string(data)
+}
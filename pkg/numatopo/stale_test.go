@@ -0,0 +1,133 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestReadErrorsKeepLastKnownValues(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakeClock(start)
+	root := t.TempDir()
+	writeHostTopology(t, root, []int{2, 2}, "0-3")
+	e := newTestExporter(t, "node-a", WithHostRoot(root), WithClock(clock))
+	e.opt.HeartbeatPeriod = time.Hour
+
+	e.NodeInfoRefresh()
+	resources := e.GetAllResAllocatableInfo()[resourceCPU]
+	cpuDetail := e.GetCpusDetail()
+	if resources.Capacity != 4 || len(cpuDetail) != 4 {
+		t.Fatalf("unexpected first collection, resources: %+v, cpu detail: %v", resources, cpuDetail)
+	}
+	if stale := e.GetStaleFields(); len(stale) != 0 {
+		t.Fatalf("expected nothing stale, got %v", stale)
+	}
+
+	// the NUMA node online mask and the core id of cpu 3 cannot be read
+	onlinePath := filepath.Join(root, "sys/devices/system/node/online")
+	if err := os.Remove(onlinePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "sys/devices/system/cpu/cpu3/topology/core_id")); err != nil {
+		t.Fatal(err)
+	}
+	clock.Step(time.Minute)
+	if !e.NodeInfoRefresh() {
+		t.Fatalf("expected the stale fields to be a change")
+	}
+
+	if got := e.GetAllResAllocatableInfo()[resourceCPU]; !reflect.DeepEqual(got, resources) {
+		t.Fatalf("expected the last known resources %+v, got %+v", resources, got)
+	}
+	if got := e.GetCpusDetail(); !reflect.DeepEqual(got, cpuDetail) {
+		t.Fatalf("expected the last known cpu detail %v, got %v", cpuDetail, got)
+	}
+	stale := e.GetStaleFields()
+	if len(stale) != 2 || stale[0].Field != cpuFieldCPUDetail || stale[1].Field != cpuFieldTopology {
+		t.Fatalf("expected the cpu detail and topology to be stale, got %v", stale)
+	}
+	if ready := e.GetConditions()[0]; ready.Type != ConditionReady || ready.Status != metav1.ConditionFalse {
+		t.Fatalf("expected Ready to be False while the topology is stale, got %+v", ready)
+	}
+	desired := e.desiredNumatopo()
+	var published []StaleField
+	if err := json.Unmarshal([]byte(desired.Annotations[StaleAnnotation]), &published); err != nil || len(published) != 2 {
+		t.Fatalf("expected the stale fields in the annotation, got %q, err: %v", desired.Annotations[StaleAnnotation], err)
+	}
+	if !published[0].Since.Time.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected the stale fields since %v, got %v", start.Add(time.Minute), published[0].Since)
+	}
+
+	// the same failures keep the time they started
+	clock.Step(time.Second)
+	if e.NodeInfoRefresh() {
+		t.Fatalf("expected no change while the same fields are stale")
+	}
+	if since := e.GetStaleFields()[0].Since; !since.Time.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected the stale fields to keep their time, got %v", since)
+	}
+
+	writeHostFile(t, root, "sys/devices/system/node/online", "0-1")
+	writeHostFile(t, root, "sys/devices/system/cpu/cpu3/topology/core_id", "1")
+	if !e.NodeInfoRefresh() {
+		t.Fatalf("expected a change once the topology is read again")
+	}
+	if stale := e.GetStaleFields(); len(stale) != 0 {
+		t.Fatalf("expected nothing stale, got %v", stale)
+	}
+	if _, ok := e.desiredNumatopo().Annotations[StaleAnnotation]; ok {
+		t.Fatalf("expected no stale annotation")
+	}
+	if ready := e.GetConditions()[0]; ready.Status != metav1.ConditionTrue {
+		t.Fatalf("expected Ready to be True again, got %+v", ready)
+	}
+}
+
+func TestPCIDevicesOnlyReadForNFD(t *testing.T) {
+	root := t.TempDir()
+	writeHostTopology(t, root, []int{2}, "0-1")
+	e := newTestExporter(t, "node-a", WithHostRoot(root))
+
+	// the locality of the device cannot be read, as numa_node is a directory
+	e.opt.PCIDevicePath = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(e.opt.PCIDevicePath, "0000:af:00.0", "numa_node"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	e.NodeInfoRefresh()
+	if stale := e.GetStaleFields(); len(stale) != 0 {
+		t.Fatalf("expected the PCI devices not to be read without NFD, got stale %v", stale)
+	}
+	if ready := e.GetConditions()[0]; ready.Status != metav1.ConditionTrue {
+		t.Fatalf("expected Ready to be True, got %+v", ready)
+	}
+
+	e.opt.NFDFeaturesDir = t.TempDir()
+	e.NodeInfoRefresh()
+	if stale := e.GetStaleFields(); len(stale) != 1 || stale[0].Field != cpuFieldPCIDevices {
+		t.Fatalf("expected the PCI devices to be read for NFD and stale, got %v", stale)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// topologyHashLength is the number of hex digits kept of the topology hash, short enough for a label value
+const topologyHashLength = 16
+
+// topologySummary is the condensed hardware topology published by the sinks writing node facts
+type topologySummary struct {
+	NUMANodes int
+	Sockets   int
+	// SMT is true if any core runs more than one hardware thread
+	SMT bool
+	// NUMACPUs is the number of cpus of every NUMA node
+	NUMACPUs map[int]int
+	// Hash identifies the hardware topology: cpus, their NUMA node, socket, core and L3 cache,
+	// nodes with the same hash can be treated alike by topology-aware placement
+	Hash string
+}
+
+func summarizeTopology(snapshot *Snapshot) topologySummary {
+	type coreKey struct{ socket, core int }
+	summary := topologySummary{NUMACPUs: make(map[int]int)}
+	sockets := make(map[int]bool)
+	coreThreads := make(map[coreKey]int)
+
+	var cpuLines []string
+	for id, info := range snapshot.Numatopology.Spec.CPUDetail {
+		cpuID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		summary.NUMACPUs[info.NUMANodeID]++
+		sockets[info.SocketID] = true
+		coreThreads[coreKey{socket: info.SocketID, core: info.CoreID}]++
+
+		l3Cache := "-"
+		if cacheID, ok := snapshot.CPUL3Cache[id]; ok {
+			l3Cache = strconv.Itoa(cacheID)
+		}
+		cpuLines = append(cpuLines, fmt.Sprintf("%d,%d,%d,%d,%s", cpuID, info.NUMANodeID, info.SocketID, info.CoreID, l3Cache))
+	}
+
+	summary.NUMANodes = len(summary.NUMACPUs)
+	summary.Sockets = len(sockets)
+	for _, threads := range coreThreads {
+		if threads > 1 {
+			summary.SMT = true
+			break
+		}
+	}
+
+	sort.Strings(cpuLines)
+	sum := sha256.Sum256([]byte(strings.Join(cpuLines, "\n")))
+	summary.Hash = hex.EncodeToString(sum[:])[:topologyHashLength]
+
+	return summary
+}
+
+// topologyPolicy returns the policy of name in spec, none if it is unset
+func topologyPolicy(spec v1alpha1.NumatopoSpec, name v1alpha1.PolicyName) string {
+	if policy := spec.Policies[name]; policy != "" {
+		return policy
+	}
+	return "none"
+}
@@ -18,32 +18,72 @@ package numatopo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"time"
+	"reflect"
+	"strconv"
 
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
-	"volcano.sh/apis/pkg/client/clientset/versioned"
 
-	"volcano.sh/resource-exporter/pkg/args"
+	"volcano.sh/resource-exporter/pkg/version"
 )
 
+const (
+	// FieldManager is the field manager the exporter writes Numatopology objects with
+	FieldManager = "resource-exporter"
+	// GenerationAnnotation is increased by one on every write of the Numatopology by the exporter,
+	// so consumers can tell whether the object they see is newer than the one they reasoned about
+	GenerationAnnotation = "volcano.sh/numatopo-generation"
+
+	legacyTimestampAnnotation = "timestamp"
+)
+
+// exporterAnnotations are the Numatopology annotations owned by the exporter besides GenerationAnnotation
+var exporterAnnotations = []string{
+	ReservationSourceAnnotation,
+	ConfigWarningsAnnotation,
+	ConditionsAnnotation,
+	StaleAnnotation,
+	AllocationSourceAnnotation,
+	ExporterVersionAnnotation,
+}
+
 // NodeInfoRefresh check the data changes
-func NodeInfoRefresh(opt *args.Argument) bool {
+func (e *Exporter) NodeInfoRefresh() bool {
+	start := e.clock.Now()
+	defer func() { e.metrics.refreshDuration.Observe(e.clock.Since(start).Seconds()) }()
+
 	isChange := false
 
-	klConfig, err := GetKubeletConfigFromLocalFile(opt.KubeletConf)
+	klConfig, err := e.GetKubeletConfig()
 	if err != nil {
 		klog.Errorf("failed to get kubelet configuration, err: %v", err)
 	} else {
-		isChange = TryUpdatingResourceReservation(klConfig, opt.ResReserved)
+		isChange = e.TryUpdatingResourceReservation(klConfig, e.opt.ResReserved)
+	}
+
+	if e.TopoInfoUpdate() {
+		isChange = true
 	}
 
-	if TopoInfoUpdate(opt) {
+	if klConfig != nil && e.TryUpdatingConfigWarnings(klConfig, e.opt.ResReserved) {
+		isChange = true
+	}
+
+	now := e.clock.Now()
+	if e.updateStaleFields(now) {
+		isChange = true
+	}
+
+	if e.updateConditions(now, e.opt.HeartbeatPeriod, err, e.staleErr()) {
 		isChange = true
 	}
 
@@ -53,68 +93,195 @@ func NodeInfoRefresh(opt *args.Argument) bool {
 // CreateOrUpdateNumatopo creates or updates the numatopo to etcd.
 // The cached parameter is the Numatopology resource from the informer cache.
 // If cached is nil, a new resource will be created.
-// If cached is not nil, the resource will be updated.
-func CreateOrUpdateNumatopo(client versioned.Interface, cached *v1alpha1.Numatopology) {
-	hostname := os.Getenv("MY_NODE_NAME")
-	if hostname == "" {
-		klog.Errorf("Get env MY_NODE_NAME failed.")
-		return
-	}
-
-	if cached == nil {
-		// Resource does not exist in cache, create a new one
-		numaInfo := &v1alpha1.Numatopology{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: hostname,
-			},
-			Spec: v1alpha1.NumatopoSpec{
-				Policies:       GetPolicy(),
-				ResReserved:    GetResReserved(),
-				NumaResMap:     GetAllResAllocatableInfo(),
-				CPUDetail:      GetCpusDetail(),
-				PodAllocations: GetPodAllocations(),
+// If cached is not nil, the resource will be patched with the fields that changed;
+// nothing is written if it already matches the local state.
+func (e *Exporter) CreateOrUpdateNumatopo(cached *v1alpha1.Numatopology) error {
+	if e.nodeInfoClient == nil {
+		return fmt.Errorf("no Numatopology client, use WithNumatopoClient or WithRestConfig")
+	}
+
+	if err := e.publishNumatopo(cached, e.desiredNumatopo()); err != nil {
+		return err
+	}
+	e.metrics.published(e.clock.Now())
+	return nil
+}
+
+// desiredNumatopo returns a snapshot of the spec and the annotations owned by the exporter from the local state
+func (e *Exporter) desiredNumatopo() *v1alpha1.Numatopology {
+	spec := v1alpha1.NumatopoSpec{
+		Policies:       e.GetPolicy(),
+		ResReserved:    e.GetResReserved(),
+		NumaResMap:     e.GetAllResAllocatableInfo(),
+		CPUDetail:      e.GetCpusDetail(),
+		PodAllocations: e.GetPodAllocations(),
+	}
+	desired := &v1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				ReservationSourceAnnotation: e.GetReservationSource(),
+				ExporterVersionAnnotation:   version.String(),
 			},
-		}
+		},
+		// the getters return the maps kept by the collectors, which keep changing after the snapshot
+		Spec: *spec.DeepCopy(),
+	}
+	if warnings := e.configWarningsAnnotation(); warnings != "" {
+		desired.Annotations[ConfigWarningsAnnotation] = warnings
+	}
+	if conditions := e.conditionsAnnotation(); conditions != "" {
+		desired.Annotations[ConditionsAnnotation] = conditions
+	}
+	if stale := e.staleAnnotation(); stale != "" {
+		desired.Annotations[StaleAnnotation] = stale
+	}
+	if source := e.GetAllocationSource(); source != "" {
+		desired.Annotations[AllocationSourceAnnotation] = source
+	}
+	setNodeOwner(desired, e.nodeOwner)
+	return desired
+}
 
-		_, err := client.NodeinfoV1alpha1().Numatopologies().Create(context.TODO(), numaInfo, metav1.CreateOptions{})
-		if err != nil {
-			if !apierrors.IsAlreadyExists(err) {
-				klog.Errorf("Create Numatopo for node %s failed, err=%v", hostname, err)
-				return
+// publishNumatopo writes desired to the Numatopology of the node, starting from cached.
+// If the create finds an object created concurrently, the object is read again from the API server
+// and patched instead.
+func (e *Exporter) publishNumatopo(cached, desired *v1alpha1.Numatopology) error {
+	current := cached
+	reread := false
+	err := retry.OnError(retry.DefaultBackoff, isCreateConflict, func() error {
+		if reread {
+			obj, err := e.nodeInfoClient.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), e.nodeName, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				current = nil
+			case err != nil:
+				return err
+			default:
+				current = obj
 			}
+		}
+		reread = true
 
-			cached, err = client.NodeinfoV1alpha1().Numatopologies().Get(context.TODO(), hostname, metav1.GetOptions{})
-			if err != nil {
-				klog.Errorf("Get existing Numatopo for node %s failed, err=%v", hostname, err)
-				return
-			}
-			// Resource already exists, fall through to update it below.
+		var err error
+		if current == nil {
+			err = e.createNumatopo(desired)
 		} else {
-			// Create succeeded, no immediate update is needed.
-			klog.V(4).Infof("Created Numatopo for node %s successfully", hostname)
-			return
+			err = e.patchNumatopo(current, desired)
 		}
+		if isCreateConflict(err) {
+			e.metrics.publishConflicts.WithLabelValues(numatopoTarget).Inc()
+		}
+		return err
+	})
+	if err != nil {
+		e.metrics.publishFailures.WithLabelValues(numatopoTarget).Inc()
 	}
+	return err
+}
+
+// isCreateConflict returns true if the Numatopology was created concurrently, it is read again and patched
+func isCreateConflict(err error) bool {
+	return apierrors.IsAlreadyExists(err)
+}
 
-	// Resource exists (either provided in cache or fetched above), update it
-	numaInfo := cached.DeepCopy()
-	numaInfo.Spec = v1alpha1.NumatopoSpec{
-		Policies:       GetPolicy(),
-		ResReserved:    GetResReserved(),
-		NumaResMap:     GetAllResAllocatableInfo(),
-		CPUDetail:      GetCpusDetail(),
-		PodAllocations: GetPodAllocations(),
+func (e *Exporter) createNumatopo(desired *v1alpha1.Numatopology) error {
+	name := e.nodeName
+	numaInfo := &v1alpha1.Numatopology{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	setDesiredState(numaInfo, desired)
+	generation := e.nextGeneration(numaInfo)
+	numaInfo.Annotations[GenerationAnnotation] = strconv.FormatInt(generation, 10)
+
+	e.metrics.publishAttempts.WithLabelValues(numatopoTarget).Inc()
+	_, err := e.nodeInfoClient.NodeinfoV1alpha1().Numatopologies().Create(context.TODO(), numaInfo, metav1.CreateOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("create Numatopo for node %s failed: %w", name, err)
+	}
+
+	e.lastGeneration = generation
+	klog.V(4).Infof("Created Numatopo for node %s successfully", name)
+	return nil
+}
+
+func (e *Exporter) patchNumatopo(current, desired *v1alpha1.Numatopology) error {
+	if numatopoMatches(current, desired) {
+		klog.V(4).Infof("Numatopo for node %s is up to date, skip patching", current.Name)
+		return nil
+	}
+	numaInfo := current.DeepCopy()
+	setDesiredState(numaInfo, desired)
+	generation := e.nextGeneration(current)
+	numaInfo.Annotations[GenerationAnnotation] = strconv.FormatInt(generation, 10)
+
+	patch, err := createMergePatch(current, numaInfo)
+	if err != nil {
+		return fmt.Errorf("create patch of Numatopo for node %s failed: %w", current.Name, err)
+	}
+
+	e.metrics.publishAttempts.WithLabelValues(numatopoTarget).Inc()
+	_, err = e.nodeInfoClient.NodeinfoV1alpha1().Numatopologies().Patch(context.TODO(), current.Name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("patch Numatopo for node %s failed: %w", current.Name, err)
+	}
+
+	e.lastGeneration = generation
+	klog.V(4).Infof("Patched Numatopo for node %s to generation %d successfully", current.Name, generation)
+	return nil
+}
+
+// numatopoMatches returns true if current already has the spec, the exporter annotations and the owner of desired
+func numatopoMatches(current, desired *v1alpha1.Numatopology) bool {
+	numaInfo := current.DeepCopy()
+	setDesiredState(numaInfo, desired)
+	return equality.Semantic.DeepEqual(current.Spec, numaInfo.Spec) && reflect.DeepEqual(current.Annotations, numaInfo.Annotations) &&
+		reflect.DeepEqual(current.OwnerReferences, numaInfo.OwnerReferences)
+}
+
+// setDesiredState sets the spec, the annotations and the Node owner reference owned by the exporter from desired
+func setDesiredState(numaInfo, desired *v1alpha1.Numatopology) {
+	numaInfo.Spec = *desired.Spec.DeepCopy()
+	for i := range desired.OwnerReferences {
+		setNodeOwner(numaInfo, &desired.OwnerReferences[i])
 	}
 	if numaInfo.Annotations == nil {
 		numaInfo.Annotations = make(map[string]string)
 	}
-	// use to trigger CR numa update
-	numaInfo.Annotations["timestamp"] = fmt.Sprint(time.Now().Unix())
+	// the wall-clock annotation used to force updates is replaced by GenerationAnnotation
+	delete(numaInfo.Annotations, legacyTimestampAnnotation)
+	for _, key := range exporterAnnotations {
+		if value, ok := desired.Annotations[key]; ok {
+			numaInfo.Annotations[key] = value
+		} else {
+			delete(numaInfo.Annotations, key)
+		}
+	}
+}
+
+// nextGeneration returns the generation of the next write, which is greater than both the
+// generation of obj and the last one written, in case the informer cache lags behind
+func (e *Exporter) nextGeneration(obj *v1alpha1.Numatopology) int64 {
+	generation, _ := strconv.ParseInt(obj.Annotations[GenerationAnnotation], 10, 64)
+	if e.lastGeneration > generation {
+		generation = e.lastGeneration
+	}
+	return generation + 1
+}
 
-	_, err := client.NodeinfoV1alpha1().Numatopologies().Update(context.TODO(), numaInfo, metav1.UpdateOptions{})
+// createMergePatch returns a JSON merge patch which only contains the fields changed from original to modified
+func createMergePatch(original, modified *v1alpha1.Numatopology) ([]byte, error) {
+	originalJSON, err := json.Marshal(original)
 	if err != nil {
-		klog.Errorf("Update Numatopo for node %s failed, err=%v", hostname, err)
-	} else {
-		klog.V(4).Infof("Updated Numatopo for node %s successfully", hostname)
+		return nil, err
+	}
+	modifiedJSON, err := json.Marshal(modified)
+	if err != nil {
+		return nil, err
 	}
+
+	// no resourceVersion precondition: the exporter owns the fields it patches, so writes of
+	// other controllers to the object do not conflict with it
+	return jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
 }
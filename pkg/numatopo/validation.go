@@ -0,0 +1,209 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+
+	"volcano.sh/resource-exporter/pkg/util"
+)
+
+const (
+	// ConfigWarningsAnnotation is the Numatopology annotation listing the kubelet misconfigurations found
+	ConfigWarningsAnnotation = "volcano.sh/kubelet-config-warnings"
+
+	// ReasonStaticPolicyWithoutReservation is reported when the static CPU manager policy has no CPU reserved
+	ReasonStaticPolicyWithoutReservation = "StaticPolicyWithoutCPUReservation"
+	// ReasonTopologyPolicyWithoutCPUManager is reported when NUMA alignment is requested without a CPU manager to enforce it
+	ReasonTopologyPolicyWithoutCPUManager = "TopologyPolicyWithoutCPUManager"
+	// ReasonResReservedMismatch is reported when --res-reserved disagrees with what kubelet reserves
+	ReasonResReservedMismatch = "ResReservedMismatch"
+	// ReasonReservedCPUsNotCoreAligned is reported when the reserved CPUs split a physical core
+	ReasonReservedCPUsNotCoreAligned = "ReservedCPUsNotCoreAligned"
+)
+
+// ConfigWarning is a kubelet misconfiguration that makes the published topology misleading
+type ConfigWarning struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// GetConfigWarnings return the kubelet misconfigurations found by the last validation
+func (e *Exporter) GetConfigWarnings() []ConfigWarning {
+	return e.configWarnings
+}
+
+// TryUpdatingConfigWarnings validates the kubelet configuration and --res-reserved against
+// the state collected in this round. Newly found warnings are reported as Events on the Node.
+// If the set of warnings is changed, return true.
+func (e *Exporter) TryUpdatingConfigWarnings(klConfig *kubeletconfigv1beta1.KubeletConfiguration, optResReserved map[string]string) bool {
+	warnings := validateKubeletConfig(klConfig, optResReserved, e.config.kubeletReserved, e.GetCpusDetail())
+	if reflect.DeepEqual(e.configWarnings, warnings) {
+		return false
+	}
+
+	known := make(map[ConfigWarning]bool, len(e.configWarnings))
+	for _, w := range e.configWarnings {
+		known[w] = true
+	}
+	for _, w := range warnings {
+		if !known[w] {
+			klog.Warningf("Kubelet misconfiguration %s: %s", w.Reason, w.Message)
+			e.recordNodeEvent(v1.EventTypeWarning, w.Reason, w.Message)
+		}
+	}
+
+	e.configWarnings = warnings
+	return true
+}
+
+// configWarningsAnnotation returns the value of ConfigWarningsAnnotation, empty if there is no warning
+func (e *Exporter) configWarningsAnnotation() string {
+	if len(e.configWarnings) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(e.configWarnings)
+	if err != nil {
+		klog.Errorf("Marshal kubelet config warnings failed, err: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+func validateKubeletConfig(klConfig *kubeletconfigv1beta1.KubeletConfiguration, optResReserved map[string]string,
+	kubeletReserved v1.ResourceList, cpuDetail map[string]v1alpha1.CPUInfo) []ConfigWarning {
+	var warnings []ConfigWarning
+
+	staticPolicy := klConfig.CPUManagerPolicy == "static"
+	cpuManagerNone := klConfig.CPUManagerPolicy == "" || klConfig.CPUManagerPolicy == "none"
+
+	reservedCPU := resource.Quantity{}
+	if kubeletReserved != nil {
+		reservedCPU = kubeletReserved[v1.ResourceCPU]
+	}
+	if staticPolicy && kubeletReserved != nil && reservedCPU.IsZero() && klConfig.ReservedSystemCPUs == "" {
+		warnings = append(warnings, ConfigWarning{
+			Reason:  ReasonStaticPolicyWithoutReservation,
+			Message: "cpuManagerPolicy is static but no CPU is reserved, so exclusive CPUs may be taken from system daemons",
+		})
+	}
+
+	if cpuManagerNone && (klConfig.TopologyManagerPolicy == "single-numa-node" || klConfig.TopologyManagerPolicy == "restricted") {
+		warnings = append(warnings, ConfigWarning{
+			Reason: ReasonTopologyPolicyWithoutCPUManager,
+			Message: fmt.Sprintf("topologyManagerPolicy is %s but cpuManagerPolicy is none, so CPUs are not aligned to NUMA nodes",
+				klConfig.TopologyManagerPolicy),
+		})
+	}
+
+	if kubeletReserved != nil {
+		var names []string
+		for name := range optResReserved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			given, err := resource.ParseQuantity(optResReserved[name])
+			if err != nil {
+				continue
+			}
+			actual := kubeletReserved[v1.ResourceName(name)]
+			if given.Cmp(actual) != 0 {
+				warnings = append(warnings, ConfigWarning{
+					Reason:  ReasonResReservedMismatch,
+					Message: fmt.Sprintf("--res-reserved %s=%s but kubelet reserves %s", name, given.String(), actual.String()),
+				})
+			}
+		}
+	}
+
+	if staticPolicy {
+		if msg := checkReservedCPUsCoreAligned(klConfig.ReservedSystemCPUs, reservedCPU, cpuDetail); msg != "" {
+			warnings = append(warnings, ConfigWarning{Reason: ReasonReservedCPUsNotCoreAligned, Message: msg})
+		}
+	}
+
+	return warnings
+}
+
+// checkReservedCPUsCoreAligned returns a message if the CPUs kubelet reserves do not cover whole physical cores
+func checkReservedCPUsCoreAligned(reservedSystemCPUs string, reservedCPU resource.Quantity, cpuDetail map[string]v1alpha1.CPUInfo) string {
+	type coreKey struct{ socket, core int }
+	siblings := make(map[coreKey][]int)
+	cpuCore := make(map[int]coreKey)
+	for id, info := range cpuDetail {
+		cpuID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		key := coreKey{socket: info.SocketID, core: info.CoreID}
+		siblings[key] = append(siblings[key], cpuID)
+		cpuCore[cpuID] = key
+	}
+
+	threadsPerCore := 0
+	for _, cpus := range siblings {
+		sort.Ints(cpus)
+		if len(cpus) > threadsPerCore {
+			threadsPerCore = len(cpus)
+		}
+	}
+	if threadsPerCore <= 1 {
+		return ""
+	}
+
+	if reservedSystemCPUs != "" {
+		cpus, err := util.Parse(reservedSystemCPUs)
+		if err != nil {
+			return ""
+		}
+		reserved := make(map[int]bool, len(cpus))
+		for _, cpu := range cpus {
+			reserved[cpu] = true
+		}
+		for _, cpu := range cpus {
+			key, ok := cpuCore[cpu]
+			if !ok {
+				continue
+			}
+			for _, sibling := range siblings[key] {
+				if !reserved[sibling] {
+					return fmt.Sprintf("reservedSystemCPUs %s includes CPU %d but not its sibling CPU %d", reservedSystemCPUs, cpu, sibling)
+				}
+			}
+		}
+		return ""
+	}
+
+	// the static policy reserves ceil(cpu) CPUs, taking whole cores first
+	numReserved := (reservedCPU.MilliValue() + 999) / 1000
+	if numReserved%int64(threadsPerCore) != 0 {
+		return fmt.Sprintf("%d CPUs are reserved, which is not a multiple of the %d threads per core", numReserved, threadsPerCore)
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
+)
+
+// smtCPUDetail describes 2 cores with 2 threads each: core 0 = cpus 0,2 and core 1 = cpus 1,3
+var smtCPUDetail = map[string]v1alpha1.CPUInfo{
+	"0": {CoreID: 0},
+	"1": {CoreID: 1},
+	"2": {CoreID: 0},
+	"3": {CoreID: 1},
+}
+
+func reasons(warnings []ConfigWarning) []string {
+	var ret []string
+	for _, w := range warnings {
+		ret = append(ret, w.Reason)
+	}
+	return ret
+}
+
+func TestValidateKubeletConfig(t *testing.T) {
+	testCases := []struct {
+		name            string
+		klConfig        kubeletconfigv1beta1.KubeletConfiguration
+		optResReserved  map[string]string
+		kubeletReserved v1.ResourceList
+		expect          []string
+	}{
+		{
+			name:            "consistent configuration",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static", TopologyManagerPolicy: "single-numa-node"},
+			kubeletReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			expect:          nil,
+		},
+		{
+			name:            "static policy without cpu reservation",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static"},
+			kubeletReserved: v1.ResourceList{},
+			expect:          []string{ReasonStaticPolicyWithoutReservation},
+		},
+		{
+			name:            "static policy with unknown reservation is not reported",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static"},
+			kubeletReserved: nil,
+			expect:          nil,
+		},
+		{
+			name:     "single-numa-node without cpu manager",
+			klConfig: kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "none", TopologyManagerPolicy: "single-numa-node"},
+			expect:   []string{ReasonTopologyPolicyWithoutCPUManager},
+		},
+		{
+			name:            "res-reserved disagrees with kubelet",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{},
+			optResReserved:  map[string]string{"cpu": "1", "memory": "1Gi"},
+			kubeletReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1000m")},
+			expect:          []string{ReasonResReservedMismatch},
+		},
+		{
+			name:            "reserved cpus split a core",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static"},
+			kubeletReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")},
+			expect:          []string{ReasonReservedCPUsNotCoreAligned},
+		},
+		{
+			name:            "reservedSystemCPUs split a core",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static", ReservedSystemCPUs: "0-1"},
+			kubeletReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			expect:          []string{ReasonReservedCPUsNotCoreAligned},
+		},
+		{
+			name:            "reservedSystemCPUs cover whole cores",
+			klConfig:        kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "static", ReservedSystemCPUs: "0,2"},
+			kubeletReserved: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			expect:          nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := reasons(validateKubeletConfig(&tc.klConfig, tc.optResReserved, tc.kubeletReserved, smtCPUDetail))
+			if len(got) != len(tc.expect) {
+				t.Fatalf("expected %v, got %v", tc.expect, got)
+			}
+			for i := range got {
+				if got[i] != tc.expect[i] {
+					t.Fatalf("expected %v, got %v", tc.expect, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTryUpdatingConfigWarnings(t *testing.T) {
+	e := newTestExporter(t, "node-a")
+
+	klConfig := &kubeletconfigv1beta1.KubeletConfiguration{CPUManagerPolicy: "none", TopologyManagerPolicy: "restricted"}
+	if !e.TryUpdatingConfigWarnings(klConfig, nil) {
+		t.Fatalf("expected a change when a warning appears")
+	}
+	if e.configWarningsAnnotation() == "" {
+		t.Fatalf("expected the warnings annotation to be set")
+	}
+	if e.TryUpdatingConfigWarnings(klConfig, nil) {
+		t.Fatalf("expected no change when warnings are the same")
+	}
+
+	klConfig.CPUManagerPolicy = "static"
+	if !e.TryUpdatingConfigWarnings(klConfig, nil) {
+		t.Fatalf("expected a change when the warning is resolved")
+	}
+	if e.configWarningsAnnotation() != "" {
+		t.Fatalf("expected the warnings annotation to be cleared, got %q", e.configWarningsAnnotation())
+	}
+}
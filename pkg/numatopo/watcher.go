@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+const (
+	// watchDebounce coalesces the events of one write, e.g. the create and rename of a checkpoint
+	watchDebounce = 100 * time.Millisecond
+	// sysfsPollInterval is how often the sysfs online masks are read, sysfs does not support inotify
+	sysfsPollInterval = time.Second
+)
+
+// FileWatcher calls back when one of the files the local state is collected from changes,
+// so it is collected again without waiting for the next periodic refresh.
+type FileWatcher struct {
+	watcher *fsnotify.Watcher
+	clock   clock.WithTicker
+	// watched are the files watched with inotify on their directories, so files replaced by a rename,
+	// as kubelet writes its checkpoints, are still followed
+	watched map[string]bool
+	// pollFiles are read every pollInterval and compared with their previous content
+	pollFiles    []string
+	pollInterval time.Duration
+	debounce     time.Duration
+}
+
+// NewFileWatcher starts watching the kubelet state and configuration and the sysfs online masks of opt,
+// polling and debouncing on clock; it returns an error if inotify is not available
+func NewFileWatcher(opt *args.Argument, clock clock.WithTicker) (*FileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &FileWatcher{
+		watcher: watcher,
+		clock:   clock,
+		watched: make(map[string]bool),
+		pollFiles: []string{
+			filepath.Join(opt.DevicePath, "node", "online"),
+			filepath.Join(opt.DevicePath, "cpu", "online"),
+		},
+		pollInterval: sysfsPollInterval,
+		debounce:     watchDebounce,
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range []string{opt.CPUMngState, opt.KubeletConf} {
+		if file == "" {
+			continue
+		}
+		file = filepath.Clean(file)
+		w.watched[file] = true
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			// the periodic refresh still picks up changes of the file
+			klog.Warningf("Failed to watch %s, err: %v", dir, err)
+			continue
+		}
+		dirs[dir] = true
+		klog.V(2).Infof("Watching %s for changes of the local state", dir)
+	}
+
+	return w, nil
+}
+
+// Run calls onChange after the watched files change until ctx is done,
+// changes within the debounce interval are reported once
+func (w *FileWatcher) Run(ctx context.Context, onChange func()) {
+	defer w.watcher.Close()
+
+	pollTicker := w.clock.NewTicker(w.pollInterval)
+	defer pollTicker.Stop()
+	polled := w.readPollFiles()
+
+	debounce := w.clock.NewTimer(w.debounce)
+	if !debounce.Stop() {
+		<-debounce.C()
+	}
+	pending := false
+	trigger := func() {
+		if !pending {
+			debounce.Reset(w.debounce)
+			pending = true
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.watched[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				klog.V(4).Infof("Watched file changed: %s", event)
+				trigger()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// an overflow loses events, collect again to be sure
+			klog.Warningf("File watch error: %v", err)
+			trigger()
+		case <-pollTicker.C():
+			current := w.readPollFiles()
+			for i := range current {
+				if !bytes.Equal(current[i], polled[i]) {
+					klog.V(4).Infof("Polled file changed: %s", w.pollFiles[i])
+					trigger()
+				}
+			}
+			polled = current
+		case <-debounce.C():
+			pending = false
+			onChange()
+		}
+	}
+}
+
+func (w *FileWatcher) readPollFiles() [][]byte {
+	contents := make([][]byte, len(w.pollFiles))
+	for i, file := range w.pollFiles {
+		// a missing file reads as empty, so its appearance is a change
+		contents[i], _ = ioutil.ReadFile(file)
+	}
+	return contents
+}
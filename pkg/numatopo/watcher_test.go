@@ -0,0 +1,103 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numatopo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"volcano.sh/resource-exporter/pkg/args"
+)
+
+func TestFileWatcher(t *testing.T) {
+	kubeletDir, devicePath := t.TempDir(), t.TempDir()
+	statePath := filepath.Join(kubeletDir, "cpu_manager_state")
+	onlinePath := filepath.Join(devicePath, "cpu", "online")
+	if err := os.MkdirAll(filepath.Dir(onlinePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(onlinePath, []byte("0-3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := clocktesting.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	watcher, err := NewFileWatcher(&args.Argument{CPUMngState: statePath, DevicePath: devicePath}, clock)
+	if err != nil {
+		t.Fatalf("create watcher failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{}, 10)
+	go watcher.Run(ctx, func() { changes <- struct{}{} })
+
+	// the clock is stepped a debounce interval at a time, as the inotify events arrive asynchronously;
+	// the sysfs masks are polled every tenth step
+	expectChange := func(what string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			clock.Step(watchDebounce)
+			select {
+			case <-changes:
+				return
+			case <-deadline:
+				t.Fatalf("expected a change after %s", what)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+	expectNoChange := func(what string) {
+		t.Helper()
+		for step := 0; step < 2*int(sysfsPollInterval/watchDebounce); step++ {
+			clock.Step(watchDebounce)
+			select {
+			case <-changes:
+				t.Fatalf("expected no change after %s", what)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	// kubelet writes its checkpoint to a temporary file and renames it over the checkpoint
+	tmp := filepath.Join(kubeletDir, ".cpu_manager_state.tmp")
+	if err = ioutil.WriteFile(tmp, []byte(`{"policyName":"static"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(tmp, statePath); err != nil {
+		t.Fatal(err)
+	}
+	expectChange("the checkpoint was replaced")
+	// the create and rename are reported once
+	expectNoChange("one write")
+
+	if err = ioutil.WriteFile(filepath.Join(kubeletDir, "memory_manager_state"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoChange("an unrelated file changed")
+
+	if err = ioutil.WriteFile(onlinePath, []byte("0-2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectChange("a cpu went offline")
+}
@@ -20,9 +20,18 @@ package util
 import (
 	v1 "k8s.io/api/core/v1"
 	evictionapi "k8s.io/kubernetes/pkg/kubelet/eviction/api"
+	"k8s.io/kubernetes/pkg/kubelet/stats/pidlimit"
 )
 
-// HardEvictionReservation returns a ResourceList that includes reservation of resources based on hard eviction thresholds.
+// signalToResource maps the eviction signals that reserve a resource to that resource.
+var signalToResource = map[evictionapi.Signal]v1.ResourceName{
+	evictionapi.SignalMemoryAvailable: v1.ResourceMemory,
+	evictionapi.SignalNodeFsAvailable: v1.ResourceEphemeralStorage,
+	evictionapi.SignalPIDAvailable:    pidlimit.PIDs,
+}
+
+// HardEvictionReservation returns a ResourceList that includes reservation of resources based on eviction thresholds.
+// Both hard and soft thresholds may be given; if several thresholds reserve the same resource, the largest wins.
 func HardEvictionReservation(thresholds []evictionapi.Threshold, capacity v1.ResourceList) v1.ResourceList {
 	if len(thresholds) == 0 {
 		return nil
@@ -32,16 +41,16 @@ func HardEvictionReservation(thresholds []evictionapi.Threshold, capacity v1.Res
 		if threshold.Operator != evictionapi.OpLessThan {
 			continue
 		}
-		switch threshold.Signal {
-		case evictionapi.SignalMemoryAvailable:
-			memoryCapacity := capacity[v1.ResourceMemory]
-			value := evictionapi.GetThresholdQuantity(threshold.Value, &memoryCapacity)
-			ret[v1.ResourceMemory] = *value
-		case evictionapi.SignalNodeFsAvailable:
-			storageCapacity := capacity[v1.ResourceEphemeralStorage]
-			value := evictionapi.GetThresholdQuantity(threshold.Value, &storageCapacity)
-			ret[v1.ResourceEphemeralStorage] = *value
+		resourceName, ok := signalToResource[threshold.Signal]
+		if !ok {
+			continue
+		}
+		resourceCapacity := capacity[resourceName]
+		value := evictionapi.GetThresholdQuantity(threshold.Value, &resourceCapacity)
+		if current, exist := ret[resourceName]; exist && current.Cmp(*value) >= 0 {
+			continue
 		}
+		ret[resourceName] = *value
 	}
 	return ret
 }
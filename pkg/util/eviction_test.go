@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	evictionapi "k8s.io/kubernetes/pkg/kubelet/eviction/api"
+)
+
+func quantityThreshold(signal evictionapi.Signal, quantity string) evictionapi.Threshold {
+	q := resource.MustParse(quantity)
+	return evictionapi.Threshold{
+		Signal:   signal,
+		Operator: evictionapi.OpLessThan,
+		Value:    evictionapi.ThresholdValue{Quantity: &q},
+	}
+}
+
+func TestHardEvictionReservation(t *testing.T) {
+	capacity := v1.ResourceList{
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+
+	t.Run("no thresholds returns nil", func(t *testing.T) {
+		if rl := HardEvictionReservation(nil, capacity); rl != nil {
+			t.Fatalf("expected nil, got %v", rl)
+		}
+	})
+
+	t.Run("memory nodefs and pid signals", func(t *testing.T) {
+		rl := HardEvictionReservation([]evictionapi.Threshold{
+			quantityThreshold(evictionapi.SignalMemoryAvailable, "100Mi"),
+			quantityThreshold(evictionapi.SignalNodeFsAvailable, "1Gi"),
+			quantityThreshold(evictionapi.SignalPIDAvailable, "500"),
+			quantityThreshold(evictionapi.SignalImageFsAvailable, "1Gi"),
+		}, capacity)
+		if len(rl) != 3 {
+			t.Fatalf("expected 3 entries, got %v", rl)
+		}
+		if got := rl["pid"]; got.Cmp(resource.MustParse("500")) != 0 {
+			t.Fatalf("pid: expected 500, got %v", got.String())
+		}
+	})
+
+	t.Run("largest threshold wins for the same resource", func(t *testing.T) {
+		soft := evictionapi.Threshold{
+			Signal:   evictionapi.SignalMemoryAvailable,
+			Operator: evictionapi.OpLessThan,
+			Value:    evictionapi.ThresholdValue{Percentage: 0.25},
+		}
+		rl := HardEvictionReservation([]evictionapi.Threshold{
+			soft,
+			quantityThreshold(evictionapi.SignalMemoryAvailable, "100Mi"),
+		}, capacity)
+		if got := rl[v1.ResourceMemory]; got.Cmp(resource.MustParse("2Gi")) != 0 {
+			t.Fatalf("memory: expected 2Gi, got %v", got.String())
+		}
+	})
+}
@@ -24,6 +24,8 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	"k8s.io/kubernetes/pkg/kubelet/stats/pidlimit"
 
 	"volcano.sh/apis/pkg/apis/nodeinfo/v1alpha1"
 )
@@ -83,24 +85,32 @@ func ParseResourceList(m map[string]string) (v1.ResourceList, error) {
 	}
 	rl := make(v1.ResourceList)
 	for k, v := range m {
-		switch v1.ResourceName(k) {
-		// CPU, memory, local storage, and PID resources are supported.
-		case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage:
-			q, err := resource.ParseQuantity(v)
-			if err != nil {
-				return nil, err
-			}
-			if q.Sign() == -1 {
-				return nil, fmt.Errorf("resource quantity for %q cannot be negative: %v", k, v)
-			}
-			rl[v1.ResourceName(k)] = q
-		default:
+		// CPU, memory, local storage, PID and hugepages resources are supported.
+		if !IsReservableResource(v1.ResourceName(k)) {
 			return nil, fmt.Errorf("cannot reserve %q resource", k)
 		}
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, err
+		}
+		if q.Sign() == -1 {
+			return nil, fmt.Errorf("resource quantity for %q cannot be negative: %v", k, v)
+		}
+		rl[v1.ResourceName(k)] = q
 	}
 	return rl, nil
 }
 
+// IsReservableResource returns true if the resource can be reserved for the system on a node.
+func IsReservableResource(name v1.ResourceName) bool {
+	switch name {
+	case v1.ResourceCPU, v1.ResourceMemory, v1.ResourceEphemeralStorage, pidlimit.PIDs:
+		return true
+	default:
+		return v1helper.IsHugePageResourceName(name)
+	}
+}
+
 func SortPodAllocations(pas []v1alpha1.PodAllocation) {
 	sort.Slice(pas, func(i, j int) bool {
 		if pas[i].UID != pas[j].UID {
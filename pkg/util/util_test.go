@@ -118,6 +118,20 @@ func TestParseResourceList(t *testing.T) {
 		}
 	})
 
+	t.Run("valid pid and hugepages", func(t *testing.T) {
+		rl, err := ParseResourceList(map[string]string{
+			"pid":           "1000",
+			"hugepages-2Mi": "512Mi",
+			"hugepages-1Gi": "2Gi",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rl) != 3 {
+			t.Fatalf("expected 3 entries, got %d", len(rl))
+		}
+	})
+
 	t.Run("negative quantity fails", func(t *testing.T) {
 		_, err := ParseResourceList(map[string]string{
 			string(v1.ResourceCPU): "-1",
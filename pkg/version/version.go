@@ -0,0 +1,37 @@
+/*
+Copyright 2026 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+const notProvided = "Not provided."
+
+// the values are set at build time with -ldflags, see Makefile.def
+var (
+	// Version shows the version of resource-exporter.
+	Version = notProvided
+	// GitSHA shows the git commit id of resource-exporter.
+	GitSHA = notProvided
+	// Built shows the built time of the binary.
+	Built = notProvided
+)
+
+// String returns the version with the short git commit as build metadata, e.g. latest+1a2b3c4
+func String() string {
+	if GitSHA == notProvided || len(GitSHA) < 7 {
+		return Version
+	}
+	return Version + "+" + GitSHA[:7]
+}